@@ -0,0 +1,140 @@
+package updown
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// AccountConfigVersion is the version of the documents written by Export
+const AccountConfigVersion = 1
+
+// AccountConfig is the configuration of an account, its checks and webhooks, in a format
+// suitable for backups and for cloning an account
+type AccountConfig struct {
+	// Version of the format of the document
+	Version  int         `json:"version"`
+	Checks   []CheckItem `json:"checks"`
+	Webhooks []Webhook   `json:"webhooks"`
+}
+
+// ImportOptions configures how a configuration is imported
+type ImportOptions struct {
+	// How checks of the configuration are matched with existing ones
+	Key ReconcileKey
+	// Only compute the changes, without applying them
+	DryRun bool
+}
+
+// ImportReport describes the changes made by an import
+type ImportReport struct {
+	// Changes to checks. Existing checks which are not in the configuration are not deleted
+	Checks Plan
+	// Webhooks created
+	CreatedWebhooks []Webhook
+	// Webhooks which already existed
+	ExistingWebhooks []Webhook
+}
+
+// Item gives the attributes of a check which can be sent back to the API
+func (c Check) Item() CheckItem {
+	return CheckItem{
+		URL:               c.URL,
+		Period:            c.Period,
+		Apdex:             c.Apdex,
+		Enabled:           c.Enabled,
+		Published:         c.Published,
+		Alias:             c.Alias,
+		StringMatch:       c.StringMatch,
+		MuteUntil:         c.MuteUntil,
+		DisabledLocations: c.DisabledLocations,
+		CustomHeaders:     c.CustomHeaders,
+	}
+}
+
+// Export gets the configuration of all checks and webhooks of the account
+func (c *Client) Export() (AccountConfig, error) {
+	return c.ExportContext(context.Background())
+}
+
+// ExportContext is like Export but takes a context for the requests
+func (c *Client) ExportContext(ctx context.Context) (AccountConfig, error) {
+	checks, _, err := c.Check.ListContext(ctx)
+	if err != nil {
+		return AccountConfig{}, err
+	}
+	webhooks, _, err := c.Webhook.ListContext(ctx)
+	if err != nil {
+		return AccountConfig{}, err
+	}
+
+	config := AccountConfig{
+		Version:  AccountConfigVersion,
+		Checks:   make([]CheckItem, 0, len(checks)),
+		Webhooks: make([]Webhook, 0, len(webhooks)),
+	}
+	for _, check := range checks {
+		config.Checks = append(config.Checks, check.Item())
+	}
+	// IDs are specific to the account
+	for _, webhook := range webhooks {
+		config.Webhooks = append(config.Webhooks, Webhook{URL: webhook.URL})
+	}
+	return config, nil
+}
+
+// Import creates the checks and webhooks of a configuration which do not exist yet, and updates
+// existing checks to match the configuration. Nothing is deleted, so that it can be run again safely.
+func (c *Client) Import(config AccountConfig, opts ImportOptions) (ImportReport, error) {
+	return c.ImportContext(context.Background(), config, opts)
+}
+
+// ImportContext is like Import but takes a context for the requests
+func (c *Client) ImportContext(ctx context.Context, config AccountConfig, opts ImportOptions) (ImportReport, error) {
+	var report ImportReport
+	if err := config.checkVersion(); err != nil {
+		return report, err
+	}
+
+	plan, err := c.Check.ReconcileContext(ctx, config.Checks, ReconcileOptions{Key: opts.Key, DryRun: opts.DryRun})
+	report.Checks = plan
+	if err != nil {
+		return report, err
+	}
+
+	urls := make([]string, len(config.Webhooks))
+	for i, webhook := range config.Webhooks {
+		urls[i] = webhook.URL
+	}
+	webhooks, _, err := c.Webhook.ensureAll(ctx, urls, opts.DryRun)
+	report.CreatedWebhooks = webhooks.Created
+	report.ExistingWebhooks = webhooks.Existing
+	return report, err
+}
+
+// ReadAccountConfig reads a configuration written by WriteTo
+func ReadAccountConfig(r io.Reader) (AccountConfig, error) {
+	var config AccountConfig
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return AccountConfig{}, err
+	}
+	return config, config.checkVersion()
+}
+
+// WriteTo writes the configuration as indented JSON
+func (a AccountConfig) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+func (a AccountConfig) checkVersion() error {
+	if a.Version != AccountConfigVersion {
+		return fmt.Errorf("Unsupported configuration version %d, expected %d", a.Version, AccountConfigVersion)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package updown_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/antoineaugusti/updown"
+	"github.com/antoineaugusti/updown/updowntest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportImport(t *testing.T) {
+	source := updowntest.NewServer()
+	defer source.Close()
+	source.AddCheck(updown.Check{URL: "https://google.fr", Alias: "Google", Enabled: true, Period: 300, DisabledLocations: []string{"syd"}})
+	source.AddCheck(updown.Check{URL: "https://bing.com", Alias: "Bing", StringMatch: "Bing"})
+	source.Client().Webhook.Add(updown.Webhook{URL: "https://example.com/hook"})
+
+	config, err := source.Client().Export()
+	assert.Nil(t, err)
+	assert.Equal(t, updown.AccountConfigVersion, config.Version)
+	assert.Len(t, config.Checks, 2)
+	assert.Equal(t, []updown.Webhook{{URL: "https://example.com/hook"}}, config.Webhooks)
+
+	// Round trip through JSON
+	var buf bytes.Buffer
+	_, err = config.WriteTo(&buf)
+	assert.Nil(t, err)
+	read, err := updown.ReadAccountConfig(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, config, read)
+
+	target := updowntest.NewServer()
+	defer target.Close()
+	client := target.Client()
+
+	// Dry-run
+	report, err := client.Import(read, updown.ImportOptions{DryRun: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, report.Checks.Count(updown.ActionCreate))
+	assert.Len(t, report.CreatedWebhooks, 1)
+	assert.Empty(t, target.Checks())
+
+	report, err = client.Import(read, updown.ImportOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, target.Checks(), 2)
+	assert.Equal(t, []string{"syd"}, target.Checks()[0].DisabledLocations)
+	assert.Len(t, target.Webhooks(), 1)
+
+	// Importing again changes nothing
+	report, err = client.Import(read, updown.ImportOptions{})
+	assert.Nil(t, err)
+	assert.False(t, report.Checks.HasChanges())
+	assert.Empty(t, report.CreatedWebhooks)
+	assert.Len(t, report.ExistingWebhooks, 1)
+	assert.Len(t, target.Checks(), 2)
+	assert.Len(t, target.Webhooks(), 1)
+}
+
+func TestExportImportWithoutAliases(t *testing.T) {
+	source := updowntest.NewServer()
+	defer source.Close()
+	source.AddCheck(updown.Check{URL: "https://google.fr", Alias: "Google", Enabled: true})
+	source.AddCheck(updown.Check{URL: "https://bing.com", Enabled: true})
+	source.AddCheck(updown.Check{URL: "https://bing.com", Enabled: true, StringMatch: "Bing"})
+
+	config, err := source.Client().Export()
+	assert.Nil(t, err)
+
+	for _, key := range []updown.ReconcileKey{updown.KeyByAlias, updown.KeyByURL} {
+		target := updowntest.NewServer()
+		client := target.Client()
+
+		report, err := client.Import(config, updown.ImportOptions{Key: key})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, report.Checks.Count(updown.ActionCreate))
+		assert.Len(t, target.Checks(), 3)
+
+		// Checks without an alias, or sharing a URL, are matched again
+		report, err = client.Import(config, updown.ImportOptions{Key: key})
+		assert.Nil(t, err)
+		assert.False(t, report.Checks.HasChanges())
+		assert.Len(t, target.Checks(), 3)
+		assert.Equal(t, "Bing", target.Checks()[2].StringMatch)
+		target.Close()
+	}
+}
+
+func TestReadAccountConfigVersion(t *testing.T) {
+	_, err := updown.ReadAccountConfig(strings.NewReader(`{"version":2,"checks":[]}`))
+	assert.NotNil(t, err)
+}
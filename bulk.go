@@ -0,0 +1,200 @@
+package updown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultBulkWorkers is the number of concurrent requests of a bulk operation, unless configured
+const DefaultBulkWorkers = 4
+
+// ErrEmptySelector indicates that a bulk selector targets no check, nor all of them explicitly
+var ErrEmptySelector = errors.New("The selector has no token nor filter, set All to target every check")
+
+// BulkSelector selects the checks targeted by a bulk operation: the given tokens, or the checks
+// matching the filter when tokens are nil. An empty, non-nil, list of tokens targets no check.
+type BulkSelector struct {
+	Tokens []string
+	Filter CheckFilter
+	// Target every check when there are neither tokens nor filter. A zero selector is rejected
+	// with ErrEmptySelector otherwise.
+	All bool
+}
+
+// BulkOptions configures a bulk operation
+type BulkOptions struct {
+	// Number of concurrent requests, DefaultBulkWorkers when zero
+	Workers int
+}
+
+// BulkResult is the outcome of a bulk operation for a check
+type BulkResult struct {
+	Token string
+	// The check after the operation, empty for removals and failures
+	Check Check
+	Err   error
+}
+
+// BulkReport lists the outcome of a bulk operation for every check, in the order of the selection
+type BulkReport struct {
+	Results []BulkResult
+}
+
+// Failed lists the results of the checks for which the operation failed
+func (r BulkReport) Failed() []BulkResult {
+	var failed []BulkResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err summarizes the failures of the operation, it is nil when it succeeded for every check
+func (r BulkReport) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("Bulk operation failed for %d of %d checks, first error for %s: %w",
+		len(failed), len(r.Results), failed[0].Token, failed[0].Err)
+}
+
+// BulkPatch applies a patch to the selected checks. An error is returned only when the checks
+// cannot be selected, failures for each check are in the report.
+func (s *CheckService) BulkPatch(selector BulkSelector, patch CheckPatch, opts BulkOptions) (BulkReport, error) {
+	return s.BulkPatchContext(context.Background(), selector, patch, opts)
+}
+
+// BulkPatchContext is like BulkPatch but takes a context for the requests
+func (s *CheckService) BulkPatchContext(ctx context.Context, selector BulkSelector, patch CheckPatch, opts BulkOptions) (BulkReport, error) {
+	if err := patch.Validate(); err != nil {
+		return BulkReport{}, err
+	}
+	return s.bulk(ctx, selector, opts, func(token string) (Check, error) {
+		check, _, err := s.PatchContext(ctx, token, patch)
+		return check, err
+	})
+}
+
+// BulkRemove removes the selected checks
+func (s *CheckService) BulkRemove(selector BulkSelector, opts BulkOptions) (BulkReport, error) {
+	return s.BulkRemoveContext(context.Background(), selector, opts)
+}
+
+// BulkRemoveContext is like BulkRemove but takes a context for the requests
+func (s *CheckService) BulkRemoveContext(ctx context.Context, selector BulkSelector, opts BulkOptions) (BulkReport, error) {
+	return s.bulk(ctx, selector, opts, func(token string) (Check, error) {
+		_, _, err := s.RemoveContext(ctx, token)
+		return Check{}, err
+	})
+}
+
+// BulkEnable enables the selected checks
+func (s *CheckService) BulkEnable(selector BulkSelector, opts BulkOptions) (BulkReport, error) {
+	return s.BulkEnableContext(context.Background(), selector, opts)
+}
+
+// BulkEnableContext is like BulkEnable but takes a context for the requests
+func (s *CheckService) BulkEnableContext(ctx context.Context, selector BulkSelector, opts BulkOptions) (BulkReport, error) {
+	return s.BulkPatchContext(ctx, selector, CheckPatch{Enabled: Bool(true)}, opts)
+}
+
+// BulkDisable disables the selected checks
+func (s *CheckService) BulkDisable(selector BulkSelector, opts BulkOptions) (BulkReport, error) {
+	return s.BulkDisableContext(context.Background(), selector, opts)
+}
+
+// BulkDisableContext is like BulkDisable but takes a context for the requests
+func (s *CheckService) BulkDisableContext(ctx context.Context, selector BulkSelector, opts BulkOptions) (BulkReport, error) {
+	return s.BulkPatchContext(ctx, selector, CheckPatch{Enabled: Bool(false)}, opts)
+}
+
+// BulkMute mutes notifications of the selected checks until the given time, 'recovery' or 'forever'
+func (s *CheckService) BulkMute(selector BulkSelector, until string, opts BulkOptions) (BulkReport, error) {
+	return s.BulkMuteContext(context.Background(), selector, until, opts)
+}
+
+// BulkMuteContext is like BulkMute but takes a context for the requests
+func (s *CheckService) BulkMuteContext(ctx context.Context, selector BulkSelector, until string, opts BulkOptions) (BulkReport, error) {
+	return s.BulkPatchContext(ctx, selector, CheckPatch{MuteUntil: String(until)}, opts)
+}
+
+// BulkUnmute unmutes notifications of the selected checks
+func (s *CheckService) BulkUnmute(selector BulkSelector, opts BulkOptions) (BulkReport, error) {
+	return s.BulkUnmuteContext(context.Background(), selector, opts)
+}
+
+// BulkUnmuteContext is like BulkUnmute but takes a context for the requests
+func (s *CheckService) BulkUnmuteContext(ctx context.Context, selector BulkSelector, opts BulkOptions) (BulkReport, error) {
+	return s.BulkPatchContext(ctx, selector, CheckPatch{MuteUntil: String("")}, opts)
+}
+
+// BulkSetPeriod sets the interval in seconds of the selected checks
+func (s *CheckService) BulkSetPeriod(selector BulkSelector, period int, opts BulkOptions) (BulkReport, error) {
+	return s.BulkSetPeriodContext(context.Background(), selector, period, opts)
+}
+
+// BulkSetPeriodContext is like BulkSetPeriod but takes a context for the requests
+func (s *CheckService) BulkSetPeriodContext(ctx context.Context, selector BulkSelector, period int, opts BulkOptions) (BulkReport, error) {
+	return s.BulkPatchContext(ctx, selector, CheckPatch{Period: Int(period)}, opts)
+}
+
+// bulk runs an operation on the selected checks with a pool of workers
+func (s *CheckService) bulk(ctx context.Context, selector BulkSelector, opts BulkOptions, operation func(token string) (Check, error)) (BulkReport, error) {
+	tokens, err := s.selectTokens(ctx, selector)
+	if err != nil {
+		return BulkReport{}, err
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultBulkWorkers
+	}
+
+	report := BulkReport{Results: make([]BulkResult, len(tokens))}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				token := tokens[index]
+				check, err := operation(token)
+				report.Results[index] = BulkResult{Token: token, Check: check, Err: err}
+			}
+		}()
+	}
+
+	for index := range tokens {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	return report, nil
+}
+
+// selectTokens gives the tokens of the checks targeted by a selector
+func (s *CheckService) selectTokens(ctx context.Context, selector BulkSelector) ([]string, error) {
+	if selector.Tokens != nil {
+		return selector.Tokens, nil
+	}
+	if selector.Filter == (CheckFilter{}) && !selector.All {
+		return nil, ErrEmptySelector
+	}
+
+	checks, _, err := s.FindContext(ctx, selector.Filter)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]string, len(checks))
+	for i, check := range checks {
+		tokens[i] = check.Token
+	}
+	return tokens, nil
+}
@@ -0,0 +1,89 @@
+package updown_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/antoineaugusti/updown"
+	"github.com/stretchr/testify/assert"
+)
+
+var bulkChecks = []updown.Check{
+	{Token: "a", URL: "https://google.fr", Alias: "Google FR", Enabled: true},
+	{Token: "b", URL: "https://google.com", Alias: "Google COM", Enabled: true, MuteUntil: "forever"},
+	{Token: "c", URL: "https://bing.com", Alias: "Bing", Enabled: true},
+}
+
+func TestBulkPatch(t *testing.T) {
+	server := newChecksServer(bulkChecks...)
+	defer server.Close()
+	client := server.Client()
+
+	selector := updown.BulkSelector{Filter: updown.CheckFilter{AliasGlob: "Google *"}}
+	report, err := client.Check.BulkDisable(selector, updown.BulkOptions{Workers: 2})
+	assert.Nil(t, err)
+	assert.Nil(t, report.Err())
+	assert.Len(t, report.Results, 2)
+	assert.Equal(t, "a", report.Results[0].Token)
+	assert.False(t, report.Results[0].Check.Enabled)
+
+	checks := server.Checks()
+	assert.False(t, checks[0].Enabled)
+	assert.False(t, checks[1].Enabled)
+	assert.True(t, checks[2].Enabled)
+
+	report, err = client.Check.BulkUnmute(updown.BulkSelector{All: true}, updown.BulkOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, report.Results, 3)
+	assert.Equal(t, "", server.Checks()[1].MuteUntil)
+
+	report, err = client.Check.BulkSetPeriod(updown.BulkSelector{Tokens: []string{"c"}}, 300, updown.BulkOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 300, server.Checks()[2].Period)
+
+	// Invalid patches are not sent
+	_, err = client.Check.BulkSetPeriod(updown.BulkSelector{All: true}, 42, updown.BulkOptions{})
+	assert.True(t, errors.Is(err, updown.ErrValidation))
+}
+
+func TestBulkReportsFailures(t *testing.T) {
+	server := newChecksServer(bulkChecks...)
+	defer server.Close()
+	client := server.Client()
+
+	selector := updown.BulkSelector{Tokens: []string{"a", "unknown", "c"}}
+	report, err := client.Check.BulkRemove(selector, updown.BulkOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, report.Failed(), 1)
+	assert.Equal(t, "unknown", report.Failed()[0].Token)
+	assert.True(t, errors.Is(report.Err(), updown.ErrNotFound))
+
+	// The operation did not stop at the first error
+	assert.Len(t, server.Checks(), 1)
+	assert.Equal(t, "b", server.Checks()[0].Token)
+}
+
+func TestBulkSelector(t *testing.T) {
+	server := newChecksServer(bulkChecks...)
+	defer server.Close()
+	client := server.Client()
+
+	// An empty list of tokens touches no check
+	report, err := client.Check.BulkRemove(updown.BulkSelector{Tokens: []string{}}, updown.BulkOptions{})
+	assert.Nil(t, err)
+	assert.Empty(t, report.Results)
+	assert.Len(t, server.Checks(), 3)
+
+	// Neither does a zero selector
+	_, err = client.Check.BulkRemove(updown.BulkSelector{}, updown.BulkOptions{})
+	assert.Equal(t, updown.ErrEmptySelector, err)
+	_, err = client.Check.BulkDisable(updown.BulkSelector{}, updown.BulkOptions{})
+	assert.Equal(t, updown.ErrEmptySelector, err)
+	assert.Len(t, server.Checks(), 3)
+	assert.True(t, server.Checks()[0].Enabled)
+
+	report, err = client.Check.BulkRemove(updown.BulkSelector{All: true}, updown.BulkOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, report.Results, 3)
+	assert.Empty(t, server.Checks())
+}
@@ -2,45 +2,146 @@ package updown
 
 import (
 	"sync"
+	"time"
 )
 
-// Cache lets you cache indefinitely values
+// Cache lets you cache values, indefinitely or for a given duration
 type Cache interface {
 	Has(key string) bool
 	Put(key, value string)
+	// PutWithTTL stores a value which expires after the given duration.
+	// A duration of zero or less uses the default of the cache.
+	PutWithTTL(key, value string, ttl time.Duration)
 	Get(key string) (has bool, value string)
+	Delete(key string)
+	Clear()
 }
 
-// MemoryCache is a cache that works in memory
+// BatchCache is implemented by caches which store several values at once more efficiently than
+// one at a time. CheckService uses it, when available, to fill the cache with all the aliases.
+type BatchCache interface {
+	// PutMany stores values which expire after the given duration.
+	// A duration of zero or less uses the default of the cache.
+	PutMany(values map[string]string, ttl time.Duration)
+}
+
+// cacheEntry is a value stored in a MemoryCache, a zero expiry never expires
+type cacheEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// MemoryCache is a cache that works in memory. Expired values are evicted lazily when
+// they are read, or all at once with Purge.
 type MemoryCache struct {
-	items map[string]string
+	items map[string]cacheEntry
+	ttl   time.Duration
+	now   func() time.Time
 	mu    sync.RWMutex
 }
 
 // Has determines if we can find in the cache a key for the given value
 func (c *MemoryCache) Has(key string) bool {
-	c.mu.RLock()
-	_, has := c.items[key]
-	c.mu.RUnlock()
+	has, _ := c.Get(key)
 	return has
 }
 
-// Put associates a key to a given value in the cache
+// Put associates a key to a given value in the cache, for the default duration of the cache
 func (c *MemoryCache) Put(key, value string) {
+	c.PutWithTTL(key, value, 0)
+}
+
+// PutWithTTL associates a key to a given value in the cache, for the given duration.
+// A duration of zero or less uses the default duration of the cache.
+func (c *MemoryCache) PutWithTTL(key, value string, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+
+	entry := cacheEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	c.items[key] = entry
+	c.mu.Unlock()
+}
+
+// PutMany associates keys to values in the cache, for the given duration.
+// A duration of zero or less uses the default duration of the cache.
+func (c *MemoryCache) PutMany(values map[string]string, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+
 	c.mu.Lock()
-	c.items[key] = value
+	for key, value := range values {
+		c.items[key] = cacheEntry{value: value, expiresAt: expiresAt}
+	}
 	c.mu.Unlock()
 }
 
 // Get gets a value from the cache by its key and tells if it was found or not
 func (c *MemoryCache) Get(key string) (has bool, value string) {
 	c.mu.RLock()
-	value, has = c.items[key]
+	entry, has := c.items[key]
 	c.mu.RUnlock()
-	return
+
+	if has && entry.expired(c.now()) {
+		c.mu.Lock()
+		// The entry may have been replaced in the meantime
+		if entry, has := c.items[key]; has && entry.expired(c.now()) {
+			delete(c.items, key)
+		}
+		c.mu.Unlock()
+		return false, ""
+	}
+	return has, entry.value
 }
 
-// NewMemoryCache creates a new memory cache
+// Delete removes a key from the cache
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	delete(c.items, key)
+	c.mu.Unlock()
+}
+
+// Clear removes all keys from the cache
+func (c *MemoryCache) Clear() {
+	c.mu.Lock()
+	c.items = make(map[string]cacheEntry)
+	c.mu.Unlock()
+}
+
+// Purge removes all expired keys from the cache
+func (c *MemoryCache) Purge() {
+	now := c.now()
+
+	c.mu.Lock()
+	for key, entry := range c.items {
+		if entry.expired(now) {
+			delete(c.items, key)
+		}
+	}
+	c.mu.Unlock()
+}
+
+// NewMemoryCache creates a new memory cache, where values never expire by default
 func NewMemoryCache() *MemoryCache {
-	return &MemoryCache{items: make(map[string]string)}
+	return NewMemoryCacheWithTTL(0)
+}
+
+// NewMemoryCacheWithTTL creates a new memory cache, where values expire after the given
+// duration by default. A duration of zero or less never expires.
+func NewMemoryCacheWithTTL(ttl time.Duration) *MemoryCache {
+	return &MemoryCache{items: make(map[string]cacheEntry), ttl: ttl, now: time.Now}
+}
+
+func (e cacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
 }
@@ -2,6 +2,7 @@ package updown
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -17,3 +18,76 @@ func TestCache(t *testing.T) {
 	assert.True(t, has)
 	assert.Equal(t, "bar", val)
 }
+
+func TestCacheDeleteClear(t *testing.T) {
+	c := NewMemoryCache()
+	c.Put("foo", "bar")
+	c.Put("baz", "qux")
+
+	c.Delete("foo")
+	assert.False(t, c.Has("foo"))
+	assert.True(t, c.Has("baz"))
+
+	c.Clear()
+	assert.False(t, c.Has("baz"))
+}
+
+func TestCachePutMany(t *testing.T) {
+	c := NewMemoryCache()
+	c.PutMany(map[string]string{"foo": "bar", "baz": "qux"}, 0)
+
+	has, val := c.Get("baz")
+	assert.True(t, has)
+	assert.Equal(t, "qux", val)
+	assert.True(t, c.Has("foo"))
+}
+
+// fakeClock is a clock moving only when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestCacheTTL(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2016, 4, 15, 0, 0, 0, 0, time.UTC)}
+	c := NewMemoryCacheWithTTL(time.Minute)
+	c.now = clock.Now
+
+	c.Put("default", "a")
+	c.PutWithTTL("short", "b", time.Second)
+	c.PutWithTTL("long", "c", time.Hour)
+
+	clock.Advance(time.Second)
+	assert.False(t, c.Has("short"))
+	assert.True(t, c.Has("default"))
+
+	clock.Advance(time.Minute)
+	has, _ := c.Get("default")
+	assert.False(t, has)
+	has, val := c.Get("long")
+	assert.True(t, has)
+	assert.Equal(t, "c", val)
+
+	// Expired values are evicted
+	assert.Len(t, c.items, 1)
+	clock.Advance(time.Hour)
+	c.Purge()
+	assert.Len(t, c.items, 0)
+}
+
+func TestCacheNoTTL(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	c := NewMemoryCache()
+	c.now = clock.Now
+
+	c.Put("foo", "bar")
+	clock.Advance(24 * 365 * time.Hour)
+	assert.True(t, c.Has("foo"))
+}
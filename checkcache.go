@@ -0,0 +1,284 @@
+package updown
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CacheStats gives statistics about the use of a CheckCache
+type CacheStats struct {
+	// Checks served while fresh
+	Hits uint64
+	// Checks served while stale, a refresh being triggered in the background
+	StaleHits uint64
+	// Checks which required a request before being served
+	Misses uint64
+	// Refreshes of all the checks, performed in the background or not
+	Refreshes uint64
+	// Refreshes which failed
+	RefreshErrors uint64
+}
+
+// CheckCache caches full checks by token. All checks are refreshed at once with CheckService.List.
+// Checks are served from memory while fresh. Once stale, they are still served for a while,
+// and refreshed in the background (stale-while-revalidate). After that, a request waits for a refresh.
+// It is safe for concurrent use.
+type CheckCache struct {
+	service *CheckService
+	maxAge  time.Duration
+	stale   time.Duration
+	now     func() time.Time
+
+	mu         sync.Mutex
+	checks     map[string]Check
+	order      []string
+	fetchedAt  time.Time
+	refreshing bool
+	stats      CacheStats
+	// Writes made through the service while a refresh is in flight, applied again once it lands
+	writes   []checkWrite
+	tracking bool
+
+	flights flightGroup
+	remove  func()
+}
+
+// checkWrite is a check added, updated or removed through the service
+type checkWrite struct {
+	check   Check
+	removed bool
+}
+
+// NewCheckCache creates a cache of the checks of a service. Checks are fresh for maxAge,
+// then served while stale for staleWhileRevalidate. Checks added, updated or removed through
+// the service are updated in the cache until it is closed, changes made by other clients are seen
+// after a refresh.
+func NewCheckCache(service *CheckService, maxAge, staleWhileRevalidate time.Duration) *CheckCache {
+	c := &CheckCache{
+		service: service,
+		maxAge:  maxAge,
+		stale:   staleWhileRevalidate,
+		now:     time.Now,
+	}
+	c.remove = service.onChange(c.checkChanged)
+	return c
+}
+
+// Close stops updating the cache with the checks changed through the service, so that the cache
+// can be garbage collected. The cache can still be used, and is updated by refreshes only.
+func (c *CheckCache) Close() {
+	c.remove()
+}
+
+// Get gets a check by its token
+func (c *CheckCache) Get(token string) (Check, error) {
+	return c.GetContext(context.Background(), token)
+}
+
+// GetContext is like Get but takes a context for the requests performed on a miss
+func (c *CheckCache) GetContext(ctx context.Context, token string) (Check, error) {
+	c.mu.Lock()
+	age := c.now().Sub(c.fetchedAt)
+	check, found := c.checks[token]
+
+	switch {
+	case c.checks != nil && age < c.maxAge && found:
+		c.stats.Hits++
+		c.mu.Unlock()
+		return check, nil
+	case c.checks != nil && age < c.maxAge+c.stale && found:
+		c.stats.StaleHits++
+		c.refreshInBackground()
+		c.mu.Unlock()
+		return check, nil
+	}
+
+	c.stats.Misses++
+	fresh := c.checks != nil && age < c.maxAge
+	c.mu.Unlock()
+
+	// The check may have been created after the last refresh, only fetch this one
+	if fresh {
+		check, _, err := c.service.GetContext(ctx, token)
+		if err != nil {
+			return Check{}, err
+		}
+		c.mu.Lock()
+		// The cache may have been invalidated in the meantime
+		if c.checks != nil {
+			if _, known := c.checks[check.Token]; !known {
+				c.order = append(c.order, check.Token)
+			}
+			c.checks[check.Token] = check
+		}
+		c.mu.Unlock()
+		return check, nil
+	}
+
+	if err := c.RefreshContext(ctx); err != nil {
+		return Check{}, err
+	}
+
+	c.mu.Lock()
+	check, found = c.checks[token]
+	c.mu.Unlock()
+	if !found {
+		return Check{}, ErrNotFound
+	}
+	return check, nil
+}
+
+// List lists all the checks, following the same rules as Get
+func (c *CheckCache) List() ([]Check, error) {
+	return c.ListContext(context.Background())
+}
+
+// ListContext is like List but takes a context for the request performed on a miss
+func (c *CheckCache) ListContext(ctx context.Context) ([]Check, error) {
+	c.mu.Lock()
+	age := c.now().Sub(c.fetchedAt)
+	switch {
+	case c.checks != nil && age < c.maxAge:
+		c.stats.Hits++
+		defer c.mu.Unlock()
+		return c.list(), nil
+	case c.checks != nil && age < c.maxAge+c.stale:
+		c.stats.StaleHits++
+		c.refreshInBackground()
+		defer c.mu.Unlock()
+		return c.list(), nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	if err := c.RefreshContext(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.list(), nil
+}
+
+// Refresh fetches all the checks with CheckService.List
+func (c *CheckCache) Refresh() error {
+	return c.RefreshContext(context.Background())
+}
+
+// RefreshContext is like Refresh but takes a context for the request. Concurrent refreshes
+// share a single request, which is not cancelled when the context of one of the callers is done,
+// but once the contexts of all of them are.
+func (c *CheckCache) RefreshContext(ctx context.Context) error {
+	_, err := c.flights.do(ctx, "refresh", func(ctx context.Context) (interface{}, error) {
+		c.mu.Lock()
+		c.tracking, c.writes = true, nil
+		c.mu.Unlock()
+
+		checks, _, err := c.service.ListContext(ctx)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		writes := c.writes
+		c.tracking, c.writes = false, nil
+		c.stats.Refreshes++
+		if err != nil {
+			c.stats.RefreshErrors++
+			return nil, err
+		}
+
+		c.checks = make(map[string]Check, len(checks))
+		c.order = make([]string, 0, len(checks))
+		for _, check := range checks {
+			c.checks[check.Token] = check
+			c.order = append(c.order, check.Token)
+		}
+		// The list may have been sent before these writes
+		for _, write := range writes {
+			c.apply(write.check, write.removed)
+		}
+		c.fetchedAt = c.now()
+		return nil, nil
+	})
+	return err
+}
+
+// checkChanged updates a cached check after it was changed or removed through the service
+func (c *CheckCache) checkChanged(check Check, removed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tracking {
+		c.writes = append(c.writes, checkWrite{check: check, removed: removed})
+	}
+	c.apply(check, removed)
+}
+
+// apply updates a cached check after it was changed or removed. The lock must be held.
+func (c *CheckCache) apply(check Check, removed bool) {
+	if c.checks == nil {
+		return
+	}
+	_, known := c.checks[check.Token]
+	switch {
+	case removed && known:
+		delete(c.checks, check.Token)
+		for i, token := range c.order {
+			if token == check.Token {
+				c.order = append(c.order[:i], c.order[i+1:]...)
+				break
+			}
+		}
+	case !removed:
+		if !known {
+			c.order = append(c.order, check.Token)
+		}
+		c.checks[check.Token] = check
+	}
+}
+
+// Invalidate forgets all checks, the next access waits for a refresh
+func (c *CheckCache) Invalidate() {
+	c.mu.Lock()
+	c.checks, c.order = nil, nil
+	c.fetchedAt = time.Time{}
+	c.mu.Unlock()
+}
+
+// Stats gives statistics about the use of the cache since its creation
+func (c *CheckCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.stats
+}
+
+// refreshInBackground starts a refresh unless one is running. It gives up once the stale
+// checks are not served anymore. The lock must be held.
+func (c *CheckCache) refreshInBackground() {
+	if c.refreshing {
+		return
+	}
+	c.refreshing = true
+
+	go func() {
+		// Once stale checks are not served anymore, requests wait for a refresh of their own
+		ctx, cancel := context.WithTimeout(context.Background(), c.stale)
+		defer cancel()
+		c.RefreshContext(ctx)
+
+		c.mu.Lock()
+		c.refreshing = false
+		c.mu.Unlock()
+	}()
+}
+
+// list returns the cached checks, in the order of the API. The lock must be held.
+func (c *CheckCache) list() []Check {
+	checks := make([]Check, 0, len(c.order))
+	for _, token := range c.order {
+		checks = append(checks, c.checks[token])
+	}
+	return checks
+}
@@ -0,0 +1,227 @@
+package updown
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCheckCacheServer(listCalls, getCalls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/checks" {
+			n := atomic.AddInt32(listCalls, 1)
+			fmt.Fprintf(w, `[{"token":"abcd","alias":"Google %d"}]`, n)
+			return
+		}
+		atomic.AddInt32(getCalls, 1)
+		if r.URL.Path == "/api/checks/efgh" {
+			w.Write([]byte(`{"token":"efgh","alias":"New"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+}
+
+func TestCheckCache(t *testing.T) {
+	var listCalls, getCalls int32
+	server := newCheckCacheServer(&listCalls, &getCalls)
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/api/")
+	client := NewClient("key", nil, WithBaseURL(baseURL))
+	clock := &fakeClock{now: time.Date(2016, 4, 15, 0, 0, 0, 0, time.UTC)}
+	cache := NewCheckCache(&client.Check, time.Minute, time.Hour)
+	cache.now = clock.Now
+
+	// Miss, then hit
+	for i := 0; i < 2; i++ {
+		check, err := cache.Get("abcd")
+		assert.Nil(t, err)
+		assert.Equal(t, "Google 1", check.Alias)
+	}
+	assert.Equal(t, int32(1), listCalls)
+
+	// Unknown check in a fresh cache, only this check is fetched
+	check, err := cache.Get("efgh")
+	assert.Nil(t, err)
+	assert.Equal(t, "New", check.Alias)
+	assert.Equal(t, int32(1), getCalls)
+	checks, _ := cache.List()
+	assert.Len(t, checks, 2)
+
+	// Stale: served right away, refreshed in the background
+	clock.Advance(2 * time.Minute)
+	check, err = cache.Get("abcd")
+	assert.Nil(t, err)
+	assert.Equal(t, "Google 1", check.Alias)
+	assert.Eventually(t, func() bool { return cache.Stats().Refreshes == 2 }, time.Second, time.Millisecond)
+	check, _ = cache.Get("abcd")
+	assert.Equal(t, "Google 2", check.Alias)
+
+	// Expired: waits for a refresh
+	clock.Advance(2 * time.Hour)
+	check, _ = cache.Get("abcd")
+	assert.Equal(t, "Google 3", check.Alias)
+
+	assert.Equal(t, CacheStats{Hits: 3, StaleHits: 1, Misses: 3, Refreshes: 3}, cache.Stats())
+}
+
+func TestCheckCacheConcurrentMisses(t *testing.T) {
+	var listCalls int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&listCalls, 1)
+		<-release
+		w.Write([]byte(`[{"token":"abcd","alias":"Google"}]`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/api/")
+	client := NewClient("key", nil, WithBaseURL(baseURL))
+	cache := NewCheckCache(&client.Check, time.Minute, time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			check, err := cache.Get("abcd")
+			assert.Nil(t, err)
+			assert.Equal(t, "Google", check.Alias)
+		}()
+	}
+	// Let the goroutines pile up on the first refresh
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), listCalls)
+	assert.Equal(t, uint64(1), cache.Stats().Refreshes)
+}
+
+func TestCheckCacheSeesOwnWrites(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`[{"token":"abcd","alias":"Google"},{"token":"efgh","alias":"Bing"}]`))
+		case "POST":
+			w.Write([]byte(`{"token":"ijkl","alias":"Yahoo","url":"https://yahoo.com"}`))
+		case "PUT":
+			w.Write([]byte(`{"token":"abcd","alias":"Google","enabled":true}`))
+		case "DELETE":
+			w.Write([]byte(`{"deleted":true}`))
+		}
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/api/")
+	client := NewClient("key", nil, WithBaseURL(baseURL))
+	cache := NewCheckCache(&client.Check, time.Minute, time.Hour)
+	assert.Nil(t, cache.Refresh())
+
+	client.Check.Patch("abcd", CheckPatch{Enabled: Bool(true)})
+	check, _ := cache.Get("abcd")
+	assert.True(t, check.Enabled)
+
+	client.Check.Add(CheckItem{URL: "https://yahoo.com", Alias: "Yahoo"})
+	client.Check.Remove("efgh")
+	checks, _ := cache.List()
+	assert.Equal(t, []string{"Google", "Yahoo"}, []string{checks[0].Alias, checks[1].Alias})
+	assert.Equal(t, uint64(1), cache.Stats().Refreshes)
+}
+
+func TestCheckCacheRefreshKeepsConcurrentWrites(t *testing.T) {
+	listing, release := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			// The list is sent before the patch lands
+			body := `[{"token":"abcd","alias":"Google"},{"token":"efgh","alias":"Bing"}]`
+			close(listing)
+			<-release
+			w.Write([]byte(body))
+		case "PUT":
+			w.Write([]byte(`{"token":"abcd","alias":"Google","enabled":true}`))
+		case "DELETE":
+			w.Write([]byte(`{"deleted":true}`))
+		}
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/api/")
+	client := NewClient("key", nil, WithBaseURL(baseURL))
+	cache := NewCheckCache(&client.Check, time.Minute, time.Hour)
+
+	refreshed := make(chan error)
+	go func() {
+		refreshed <- cache.Refresh()
+	}()
+	<-listing
+	client.Check.Patch("abcd", CheckPatch{Enabled: Bool(true)})
+	client.Check.Remove("efgh")
+	close(release)
+	assert.Nil(t, <-refreshed)
+
+	checks, _ := cache.List()
+	assert.Len(t, checks, 1)
+	assert.True(t, checks[0].Enabled)
+}
+
+func TestCheckCacheClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`[{"token":"abcd","alias":"Google"}]`))
+		case "PUT":
+			w.Write([]byte(`{"token":"abcd","alias":"Google","enabled":true}`))
+		}
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/api/")
+	client := NewClient("key", nil, WithBaseURL(baseURL))
+	cache := NewCheckCache(&client.Check, time.Minute, time.Hour)
+	assert.Nil(t, cache.Refresh())
+	cache.Close()
+	assert.Empty(t, client.Check.listeners.fns)
+
+	// Writes are not seen anymore until the next refresh
+	client.Check.Patch("abcd", CheckPatch{Enabled: Bool(true)})
+	check, _ := cache.Get("abcd")
+	assert.False(t, check.Enabled)
+}
+
+func TestCheckCacheHungRefresh(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`[{"token":"abcd","alias":"Google"}]`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/api/")
+	client := NewClient("key", nil, WithBaseURL(baseURL), WithRetryPolicy(RetryPolicy{}))
+	cache := NewCheckCache(&client.Check, time.Minute, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := cache.GetContext(ctx, "abcd")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// The hung refresh was given up, the next access refreshes again
+	check, err := cache.Get("abcd")
+	assert.Nil(t, err)
+	assert.Equal(t, "Google", check.Alias)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
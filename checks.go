@@ -1,14 +1,17 @@
 package updown
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
+	"time"
 )
 
 // SSL represents the SSL section of a check
 type SSL struct {
-	TestedAt string `json:"tested_at,omitempty"`
+	TestedAt Time   `json:"tested_at,omitempty"`
 	Valid    bool   `json:"valid,omitempty"`
 	Error    string `json:"error,omitempty"`
 }
@@ -21,14 +24,14 @@ type Check struct {
 	LastStatus        int               `json:"last_status,omitempty"`
 	Uptime            float64           `json:"uptime,omitempty"`
 	Down              bool              `json:"down"`
-	DownSince         string            `json:"down_since,omitempty"`
+	DownSince         Time              `json:"down_since,omitempty"`
 	Error             string            `json:"error,omitempty"`
 	Period            int               `json:"period,omitempty"`
 	Apdex             float64           `json:"apdex_t,omitempty"`
 	Enabled           bool              `json:"enabled"`
 	Published         bool              `json:"published"`
-	LastCheckAt       string            `json:"last_check_at,omitempty"`
-	NextCheckAt       string            `json:"next_check_at,omitempty"`
+	LastCheckAt       Time              `json:"last_check_at,omitempty"`
+	NextCheckAt       Time              `json:"next_check_at,omitempty"`
 	FaviconURL        string            `json:"favicon_url,omitempty"`
 	SSL               SSL               `json:"ssl,omitempty"`
 	StringMatch       string            `json:"string_match,omitempty"`
@@ -37,11 +40,24 @@ type Check struct {
 	CustomHeaders     map[string]string `json:"custom_headers,omitempty"`
 }
 
+// MuteUntilTime gives the time until which notifications are muted. Muted is false when
+// notifications are not muted, and the time is zero when they are muted until recovery or forever.
+func (c Check) MuteUntilTime() (until time.Time, muted bool) {
+	switch c.MuteUntil {
+	case "":
+		return time.Time{}, false
+	case "recovery", "forever":
+		return time.Time{}, true
+	}
+	until, err := parseTime(c.MuteUntil)
+	return until, err == nil
+}
+
 // CheckItem represents a new check you want to be performed by Updown
 type CheckItem struct {
 	// The URL you want to monitor
 	URL string `json:"url,omitempty"`
-	// Interval in seconds (30, 60, 120, 300 or 600)
+	// Interval in seconds (15, 30, 60, 120, 300, 600, 1800 or 3600)
 	Period int `json:"period,omitempty"`
 	// APDEX threshold in seconds (0.125, 0.25, 0.5 or 1.0)
 	Apdex float64 `json:"apdex_t,omitempty"`
@@ -61,12 +77,79 @@ type CheckItem struct {
 	CustomHeaders map[string]string `json:"custom_headers,omitempty"`
 }
 
+// CheckPatch represents changes to apply to a check. Only non-nil fields are sent, the other
+// attributes of the check are left unchanged. Use Bool, Int, Float64, String, Strings and
+// StringMap to set fields, including to false or empty values.
+type CheckPatch struct {
+	// The URL you want to monitor
+	URL *string `json:"url,omitempty"`
+	// Interval in seconds (15, 30, 60, 120, 300, 600, 1800 or 3600)
+	Period *int `json:"period,omitempty"`
+	// APDEX threshold in seconds (0.125, 0.25, 0.5 or 1.0)
+	Apdex *float64 `json:"apdex_t,omitempty"`
+	// Is the check enabled
+	Enabled *bool `json:"enabled,omitempty"`
+	// Shall the status page be public
+	Published *bool `json:"published,omitempty"`
+	// Human readable name
+	Alias *string `json:"alias,omitempty"`
+	// Search for this string in the page
+	StringMatch *string `json:"string_match,omitempty"`
+	// Mute notifications until given time, accepts a time, 'recovery' or 'forever'. Empty to unmute
+	MuteUntil *string `json:"mute_until,omitempty"`
+	// Disabled monitoring locations. It's an array of abbreviated location names
+	DisabledLocations *[]string `json:"disabled_locations,omitempty"`
+	// The HTTP headers you want in updown requests
+	CustomHeaders *map[string]string `json:"custom_headers,omitempty"`
+}
+
+// Bool returns a pointer to the given value
+func Bool(v bool) *bool { return &v }
+
+// Int returns a pointer to the given value
+func Int(v int) *int { return &v }
+
+// Float64 returns a pointer to the given value
+func Float64(v float64) *float64 { return &v }
+
+// String returns a pointer to the given value
+func String(v string) *string { return &v }
+
+// Strings returns a pointer to the given values. A nil slice is sent as an empty list.
+func Strings(v []string) *[]string {
+	if v == nil {
+		v = []string{}
+	}
+	return &v
+}
+
+// StringMap returns a pointer to the given map. A nil map is sent as an empty object.
+func StringMap(v map[string]string) *map[string]string {
+	if v == nil {
+		v = map[string]string{}
+	}
+	return &v
+}
+
 // CheckService interacts with the checks section of the API
 type CheckService struct {
-	client *Client
-	cache  Cache
+	client    *Client
+	cache     Cache
+	flights   *flightGroup
+	listeners *checkListeners
+	// Aliases which do not exist, kept apart from the cache of the user
+	missing *MemoryCache
+
+	// How long aliases are cached by TokenForAlias. Zero uses the default of the cache
+	CacheTTL time.Duration
+
+	// How long TokenForAlias remembers that an alias does not exist. Zero disables it
+	NegativeCacheTTL time.Duration
 }
 
+// DefaultNegativeCacheTTL is how long clients created with NewClient remember that an alias does not exist
+const DefaultNegativeCacheTTL = time.Minute
+
 type removeResponse struct {
 	Deleted bool `json:"deleted,omitempty"`
 }
@@ -76,37 +159,75 @@ var ErrTokenNotFound = errors.New("Could not determine a token for the given nam
 
 // TokenForAlias finds the Updown token for a check's alias
 func (s *CheckService) TokenForAlias(name string) (string, error) {
+	return s.TokenForAliasContext(context.Background(), name)
+}
+
+// TokenForAliasContext finds the Updown token for a check's alias, using ctx
+// for the request performed on a cache miss. Concurrent misses share a single request,
+// which is not cancelled when the context of one of the callers is done, but once the contexts
+// of all of them are.
+func (s *CheckService) TokenForAliasContext(ctx context.Context, name string) (string, error) {
 	// Retrieve from cache
 	if has, val := s.cache.Get(name); has {
 		return val, nil
 	}
+	if s.missing.Has(name) {
+		return "", ErrTokenNotFound
+	}
 
-	// List all checks
-	checks, _, err := s.List()
+	// List all checks, and fill the cache
+	res, err := s.flights.do(ctx, "checks", func(ctx context.Context) (interface{}, error) {
+		checks, _, err := s.ListContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.cacheAliases(checks)
+		return checks, nil
+	})
 	if err != nil {
 		return "", err
 	}
 
 	// And try to find the appropriate name
-	token, found := "", false
-	for _, check := range checks {
-		s.cache.Put(check.Alias, check.Token)
+	for _, check := range res.([]Check) {
 		if check.Alias == name {
-			found, token = true, check.Token
+			return check.Token, nil
 		}
 	}
 
-	if found {
-		return token, nil
-	}
-
 	// Could not find a match
+	if s.NegativeCacheTTL > 0 {
+		s.missing.PutWithTTL(name, "", s.NegativeCacheTTL)
+	}
 	return "", ErrTokenNotFound
 }
 
+// cacheAliases stores the tokens of checks by alias, at once when the cache supports it
+func (s *CheckService) cacheAliases(checks []Check) {
+	s.missing.Clear()
+	batch, ok := s.cache.(BatchCache)
+	if !ok {
+		for _, check := range checks {
+			s.cache.PutWithTTL(check.Alias, check.Token, s.CacheTTL)
+		}
+		return
+	}
+
+	values := make(map[string]string, len(checks))
+	for _, check := range checks {
+		values[check.Alias] = check.Token
+	}
+	batch.PutMany(values, s.CacheTTL)
+}
+
 // List lists all the checks
 func (s *CheckService) List() ([]Check, *http.Response, error) {
-	req, err := s.client.NewRequest("GET", "checks", nil)
+	return s.ListContext(context.Background())
+}
+
+// ListContext is like List but takes a context for the request
+func (s *CheckService) ListContext(ctx context.Context) ([]Check, *http.Response, error) {
+	req, err := s.client.NewRequestWithContext(ctx, "GET", "checks", nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -122,7 +243,12 @@ func (s *CheckService) List() ([]Check, *http.Response, error) {
 
 // Get gets a single check by its token
 func (s *CheckService) Get(token string) (Check, *http.Response, error) {
-	req, err := s.client.NewRequest("GET", pathForToken(token), nil)
+	return s.GetContext(context.Background(), token)
+}
+
+// GetContext is like Get but takes a context for the request
+func (s *CheckService) GetContext(ctx context.Context, token string) (Check, *http.Response, error) {
+	req, err := s.client.NewRequestWithContext(ctx, "GET", pathForToken(token), nil)
 	if err != nil {
 		return Check{}, nil, err
 	}
@@ -136,9 +262,18 @@ func (s *CheckService) Get(token string) (Check, *http.Response, error) {
 	return res, resp, err
 }
 
-// Add adds a new check you want to be performed
+// Add adds a new check you want to be performed. The check is validated before sending the request.
 func (s *CheckService) Add(data CheckItem) (Check, *http.Response, error) {
-	req, err := s.client.NewRequest("POST", "checks", data)
+	return s.AddContext(context.Background(), data)
+}
+
+// AddContext is like Add but takes a context for the request
+func (s *CheckService) AddContext(ctx context.Context, data CheckItem) (Check, *http.Response, error) {
+	if err := data.ValidateNew(); err != nil {
+		return Check{}, nil, err
+	}
+
+	req, err := s.client.NewRequestWithContext(ctx, "POST", "checks", data)
 	if err != nil {
 		return Check{}, nil, err
 	}
@@ -149,12 +284,27 @@ func (s *CheckService) Add(data CheckItem) (Check, *http.Response, error) {
 		return Check{}, resp, err
 	}
 
+	if res.Alias != "" {
+		s.cache.PutWithTTL(res.Alias, res.Token, s.CacheTTL)
+		s.missing.Delete(res.Alias)
+	}
+	s.listeners.notify(res, false)
+
 	return res, resp, err
 }
 
-// Update updates a check performed by Updown
+// Update updates a check performed by Updown. The check is validated before sending the request.
 func (s *CheckService) Update(token string, data CheckItem) (Check, *http.Response, error) {
-	req, err := s.client.NewRequest("PUT", pathForToken(token), data)
+	return s.UpdateContext(context.Background(), token, data)
+}
+
+// UpdateContext is like Update but takes a context for the request
+func (s *CheckService) UpdateContext(ctx context.Context, token string, data CheckItem) (Check, *http.Response, error) {
+	if err := data.Validate(); err != nil {
+		return Check{}, nil, err
+	}
+
+	req, err := s.client.NewRequestWithContext(ctx, "PUT", pathForToken(token), data)
 	if err != nil {
 		return Check{}, nil, err
 	}
@@ -165,12 +315,54 @@ func (s *CheckService) Update(token string, data CheckItem) (Check, *http.Respon
 		return Check{}, resp, err
 	}
 
+	// An empty alias is not sent, the alias is left unchanged
+	if data.Alias != "" {
+		s.aliasChanged(res)
+	}
+	s.listeners.notify(res, false)
+
+	return res, resp, err
+}
+
+// Patch updates a check performed by Updown, only changing the fields set in the patch.
+// The patch is validated before sending the request.
+func (s *CheckService) Patch(token string, patch CheckPatch) (Check, *http.Response, error) {
+	return s.PatchContext(context.Background(), token, patch)
+}
+
+// PatchContext is like Patch but takes a context for the request
+func (s *CheckService) PatchContext(ctx context.Context, token string, patch CheckPatch) (Check, *http.Response, error) {
+	if err := patch.Validate(); err != nil {
+		return Check{}, nil, err
+	}
+
+	req, err := s.client.NewRequestWithContext(ctx, "PUT", pathForToken(token), patch)
+	if err != nil {
+		return Check{}, nil, err
+	}
+
+	var res Check
+	resp, err := s.client.Do(req, &res)
+	if err != nil {
+		return Check{}, resp, err
+	}
+
+	if patch.Alias != nil {
+		s.aliasChanged(res)
+	}
+	s.listeners.notify(res, false)
+
 	return res, resp, err
 }
 
 // Remove removes a check from Updown by its token
 func (s *CheckService) Remove(token string) (bool, *http.Response, error) {
-	req, err := s.client.NewRequest("DELETE", pathForToken(token), nil)
+	return s.RemoveContext(context.Background(), token)
+}
+
+// RemoveContext is like Remove but takes a context for the request
+func (s *CheckService) RemoveContext(ctx context.Context, token string) (bool, *http.Response, error) {
+	req, err := s.client.NewRequestWithContext(ctx, "DELETE", pathForToken(token), nil)
 	if err != nil {
 		return false, nil, err
 	}
@@ -181,9 +373,69 @@ func (s *CheckService) Remove(token string) (bool, *http.Response, error) {
 		return false, resp, err
 	}
 
+	// We do not know the alias of the removed check, forget all of them
+	s.cache.Clear()
+	s.listeners.notify(Check{Token: token}, true)
+
 	return res.Deleted, resp, err
 }
 
+// checkListeners are notified of the checks changed through a CheckService
+type checkListeners struct {
+	mu   sync.Mutex
+	next int
+	fns  map[int]func(check Check, removed bool)
+}
+
+// onChange registers a function called with the checks added, updated or removed through the service.
+// The returned function unregisters it.
+func (s *CheckService) onChange(fn func(check Check, removed bool)) (remove func()) {
+	if s.listeners == nil {
+		return func() {}
+	}
+	l := s.listeners
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.fns == nil {
+		l.fns = make(map[int]func(Check, bool))
+	}
+	id := l.next
+	l.next++
+	l.fns[id] = fn
+	return func() {
+		l.mu.Lock()
+		delete(l.fns, id)
+		l.mu.Unlock()
+	}
+}
+
+func (l *checkListeners) notify(check Check, removed bool) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	fns := make([]func(Check, bool), 0, len(l.fns))
+	for _, fn := range l.fns {
+		fns = append(fns, fn)
+	}
+	l.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(check, removed)
+	}
+}
+
+// aliasChanged updates the cache after the alias of a check was changed. The previous alias
+// is unknown, so the cache is cleared before storing the new one.
+func (s *CheckService) aliasChanged(check Check) {
+	s.cache.Clear()
+	s.missing.Clear()
+	if check.Alias != "" {
+		s.cache.PutWithTTL(check.Alias, check.Token, s.CacheTTL)
+	}
+}
+
 func pathForToken(token string) string {
 	return fmt.Sprintf("checks/%s", token)
 }
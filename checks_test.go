@@ -0,0 +1,74 @@
+package updown_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/antoineaugusti/updown"
+	"github.com/antoineaugusti/updown/updowntest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPatchJSON(t *testing.T) {
+	encoded, _ := json.Marshal(updown.CheckPatch{
+		Enabled:           updown.Bool(false),
+		StringMatch:       updown.String(""),
+		DisabledLocations: updown.Strings(nil),
+	})
+	assert.Equal(t, `{"enabled":false,"string_match":"","disabled_locations":[]}`, string(encoded))
+}
+
+func TestPatch(t *testing.T) {
+	server := updowntest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	check := server.AddCheck(updown.Check{
+		URL:         "https://google.fr",
+		Alias:       "Google",
+		Enabled:     true,
+		Published:   true,
+		StringMatch: "Google",
+		MuteUntil:   "forever",
+		Period:      300,
+	})
+
+	patched, _, err := client.Check.Patch(check.Token, updown.CheckPatch{
+		StringMatch: updown.String(""),
+		MuteUntil:   updown.String(""),
+		Published:   updown.Bool(false),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "", patched.StringMatch)
+	assert.Equal(t, "", patched.MuteUntil)
+	assert.False(t, patched.Published)
+	// Untouched attributes
+	assert.True(t, patched.Enabled)
+	assert.Equal(t, "Google", patched.Alias)
+	assert.Equal(t, 300, patched.Period)
+}
+
+func TestTokenForAliasAfterMutations(t *testing.T) {
+	server := updowntest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	check, _, err := client.Check.Add(updown.CheckItem{URL: "https://google.fr", Alias: "Google"})
+	assert.Nil(t, err)
+	token, _ := client.Check.TokenForAlias("Google")
+	assert.Equal(t, check.Token, token)
+
+	// Renaming
+	_, _, err = client.Check.Patch(check.Token, updown.CheckPatch{Alias: updown.String("Search")})
+	assert.Nil(t, err)
+	_, err = client.Check.TokenForAlias("Google")
+	assert.Equal(t, updown.ErrTokenNotFound, err)
+	token, _ = client.Check.TokenForAlias("Search")
+	assert.Equal(t, check.Token, token)
+
+	// Removing
+	_, _, err = client.Check.Remove(check.Token)
+	assert.Nil(t, err)
+	_, err = client.Check.TokenForAlias("Search")
+	assert.Equal(t, updown.ErrTokenNotFound, err)
+}
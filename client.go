@@ -2,12 +2,14 @@ package updown
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 const (
@@ -17,13 +19,23 @@ const (
 	mediaType      = "application/json"
 )
 
-// An ErrorResponse reports the error caused by an API request
+// An ErrorResponse reports the error caused by an API request.
+// Use errors.Is with ErrNotFound, ErrUnauthorized, ErrRateLimited or ErrValidation to find its cause.
 type ErrorResponse struct {
 	// HTTP response that caused this error
 	Response *http.Response
 
-	// Error message
+	// Error message, filled for validation errors. It is part of the message of the error
 	Message string
+
+	// Error message sent by the API, whatever the status code
+	Detail string `json:"-"`
+
+	// Rejected fields, for validation errors
+	Fields []FieldError `json:"-"`
+
+	// Delay advised by the API before sending a new request, for rate limited requests
+	RetryAfter time.Duration `json:"-"`
 }
 
 func (r *ErrorResponse) Error() string {
@@ -45,6 +57,12 @@ type Client struct {
 	// APIKey to use for the API
 	APIKey string
 
+	// Policy used to retry failed requests
+	RetryPolicy RetryPolicy
+
+	// Optional limiter waited on before sending every request
+	limiter *RateLimiter
+
 	// Services used for communications with the API
 	Check    CheckService
 	Downtime DowntimeService
@@ -53,8 +71,8 @@ type Client struct {
 	Webhook  WebhookService
 }
 
-// NewClient returns a new API client.
-func NewClient(apiKey string, httpClient *http.Client) *Client {
+// NewClient returns a new API client. Options are applied in order.
+func NewClient(apiKey string, httpClient *http.Client, opts ...ClientOption) *Client {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
 	}
@@ -62,17 +80,29 @@ func NewClient(apiKey string, httpClient *http.Client) *Client {
 	baseURL, _ := url.Parse(defaultBaseURL)
 
 	c := &Client{
-		client:    httpClient,
-		BaseURL:   baseURL,
-		UserAgent: userAgent,
-		APIKey:    apiKey,
+		client:      httpClient,
+		BaseURL:     baseURL,
+		UserAgent:   userAgent,
+		APIKey:      apiKey,
+		RetryPolicy: DefaultRetryPolicy(),
+	}
+	c.Check = CheckService{
+		client:           c,
+		cache:            NewMemoryCache(),
+		flights:          &flightGroup{},
+		listeners:        &checkListeners{},
+		missing:          NewMemoryCache(),
+		NegativeCacheTTL: DefaultNegativeCacheTTL,
 	}
-	c.Check = CheckService{client: c, cache: NewMemoryCache()}
 	c.Downtime = DowntimeService{client: c}
 	c.Metric = MetricService{client: c}
 	c.Node = NodeService{client: c}
 	c.Webhook = WebhookService{client: c}
 
+	for _, opt := range opts {
+		opt(c)
+	}
+
 	return c
 }
 
@@ -80,6 +110,12 @@ func NewClient(apiKey string, httpClient *http.Client) *Client {
 // BaseURL of the Client. Relative URLS should always be specified without a preceding slash.
 // If specified, the value pointed to by body is JSON encoded and included in as the request body.
 func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+	return c.NewRequestWithContext(context.Background(), method, urlStr, body)
+}
+
+// NewRequestWithContext is like NewRequest but the request is bound to the given context.
+// Cancelling the context aborts the request when it is sent with Do.
+func (c *Client) NewRequestWithContext(ctx context.Context, method, urlStr string, body interface{}) (*http.Request, error) {
 	rel, err := url.Parse(urlStr)
 	if err != nil {
 		return nil, err
@@ -95,14 +131,14 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 		}
 	}
 
-	req, err := http.NewRequest(method, u.String(), buf)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), buf)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Add("Content-Type", mediaType)
 	req.Header.Add("Accept", mediaType)
-	req.Header.Add("User-Agent", userAgent)
+	req.Header.Add("User-Agent", c.UserAgent)
 	req.Header.Add("X-API-KEY", c.APIKey)
 	return req, nil
 }
@@ -110,9 +146,15 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 // Do sends an API request and returns the API response. The API response is JSON decoded and stored in the value
 // pointed to by v, or returned as an error if an API error has occurred. If v implements the io.Writer interface,
 // the raw response will be written to v, without attempting to decode it.
+// If the request context is cancelled or its deadline is exceeded, the context error is returned.
+// Failed requests are retried according to the RetryPolicy of the client.
 func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
-	response, err := c.client.Do(req)
+	response, err := c.send(req)
 	if err != nil {
+		// Prefer the context's error, it is more useful than the transport one
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, err
 	}
 
@@ -155,11 +197,10 @@ func CheckResponse(r *http.Response) error {
 	errorResponse := &ErrorResponse{Response: r}
 	data, err := ioutil.ReadAll(r.Body)
 	if err == nil && len(data) > 0 {
-		err := json.Unmarshal(data, errorResponse)
-		if err != nil {
-			return err
-		}
+		// Bodies which are not JSON, like the HTML page of a proxy, are ignored
+		json.Unmarshal(data, errorResponse)
 	}
+	parseErrorDetails(errorResponse, data)
 
 	return errorResponse
 }
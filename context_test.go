@@ -0,0 +1,51 @@
+package updown
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextDeadline(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	client := NewClient("key", nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/api/")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	checks, resp, err := client.Check.ListContext(ctx)
+	assert.Nil(t, checks)
+	assert.Nil(t, resp)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not have been sent")
+	}))
+	defer server.Close()
+
+	client := NewClient("key", nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/api/")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := client.Metric.ListContext(ctx, "foo", "host", "", "")
+	assert.Equal(t, context.Canceled, err)
+}
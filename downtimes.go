@@ -1,17 +1,36 @@
 package updown
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 )
 
-// Downtime represents a downtime period for a check
+// Downtime represents a downtime period for a check. EndedAt and DurationSeconds
+// are zero while the downtime is ongoing.
 type Downtime struct {
-	Error     string `json:"error,omitempty"`
-	StartedAt string `json:"started_at,omitempty"`
-	EndedAt   string `json:"ended_at,omitempty"`
-	Duration  int    `json:"duration,omitempty"`
+	ID              string `json:"id,omitempty"`
+	Error           string `json:"error,omitempty"`
+	StartedAt       Time   `json:"started_at,omitempty"`
+	EndedAt         Time   `json:"ended_at,omitempty"`
+	DurationSeconds int    `json:"duration,omitempty"`
+}
+
+// Duration gives how long the downtime lasted. For an ongoing downtime,
+// it is the time elapsed since it started.
+func (d Downtime) Duration() time.Duration {
+	if d.DurationSeconds > 0 {
+		return time.Duration(d.DurationSeconds) * time.Second
+	}
+	if d.StartedAt.IsZero() {
+		return 0
+	}
+	if d.EndedAt.IsZero() {
+		return time.Since(d.StartedAt.Time)
+	}
+	return d.EndedAt.Sub(d.StartedAt.Time)
 }
 
 // DowntimeService interacts with the downtimes section of the API
@@ -21,8 +40,13 @@ type DowntimeService struct {
 
 // List lists all known downtimes for a check
 func (s *DowntimeService) List(token string, pageNb int) ([]Downtime, *http.Response, error) {
+	return s.ListContext(context.Background(), token, pageNb)
+}
+
+// ListContext is like List but takes a context for the request
+func (s *DowntimeService) ListContext(ctx context.Context, token string, pageNb int) ([]Downtime, *http.Response, error) {
 	path := fmt.Sprintf("checks/%s/downtimes?page=%s", token, strconv.Itoa(max(1, pageNb)))
-	req, err := s.client.NewRequest("GET", path, nil)
+	req, err := s.client.NewRequestWithContext(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -36,6 +60,36 @@ func (s *DowntimeService) List(token string, pageNb int) ([]Downtime, *http.Resp
 	return res, resp, err
 }
 
+// All walks all the downtimes of a check, most recent first, fetching pages as needed.
+// The walk stops after the last page, when fn returns false or when reaching a downtime
+// which started before since. A zero since walks the whole history.
+func (s *DowntimeService) All(token string, since time.Time, fn func(Downtime) bool) error {
+	return s.AllContext(context.Background(), token, since, fn)
+}
+
+// AllContext is like All but takes a context for the requests
+func (s *DowntimeService) AllContext(ctx context.Context, token string, since time.Time, fn func(Downtime) bool) error {
+	for page := 1; ; page++ {
+		downtimes, _, err := s.ListContext(ctx, token, page)
+		if err != nil {
+			return err
+		}
+		// An empty page means that we went through all downtimes
+		if len(downtimes) == 0 {
+			return nil
+		}
+
+		for _, downtime := range downtimes {
+			if !since.IsZero() && !downtime.StartedAt.IsZero() && downtime.StartedAt.Before(since) {
+				return nil
+			}
+			if !fn(downtime) {
+				return nil
+			}
+		}
+	}
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -0,0 +1,78 @@
+package updown_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/antoineaugusti/updown"
+	"github.com/antoineaugusti/updown/updowntest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDowntimesServer(count int) (*updowntest.Server, string) {
+	server := updowntest.NewServer()
+	check := server.AddCheck(updown.Check{URL: "https://google.fr"})
+
+	// Most recent downtimes first, one per hour
+	start := time.Date(2016, 4, 15, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < count; i++ {
+		startedAt := start.Add(-time.Duration(i) * time.Hour)
+		server.AddDowntimes(check.Token, updown.Downtime{
+			Error:           fmt.Sprintf("error %d", i),
+			StartedAt:       updown.Time{Time: startedAt},
+			EndedAt:         updown.Time{Time: startedAt.Add(time.Minute)},
+			DurationSeconds: 60,
+		})
+	}
+	return server, check.Token
+}
+
+func TestDowntimesAll(t *testing.T) {
+	server, token := newDowntimesServer(250)
+	defer server.Close()
+
+	count := 0
+	err := server.Client().Downtime.All(token, time.Time{}, func(d updown.Downtime) bool {
+		assert.Equal(t, fmt.Sprintf("error %d", count), d.Error)
+		count++
+		return true
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 250, count)
+}
+
+func TestDowntimesAllStops(t *testing.T) {
+	server, token := newDowntimesServer(250)
+	defer server.Close()
+	client := server.Client()
+
+	// Early stop from the callback
+	count := 0
+	err := client.Downtime.All(token, time.Time{}, func(d updown.Downtime) bool {
+		count++
+		return count < 120
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 120, count)
+
+	// Cutoff
+	count = 0
+	since := time.Date(2016, 4, 14, 0, 0, 0, 0, time.UTC)
+	err = client.Downtime.All(token, since, func(d updown.Downtime) bool {
+		count++
+		return true
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 25, count)
+}
+
+func TestDowntimesAllError(t *testing.T) {
+	server, _ := newDowntimesServer(0)
+	defer server.Close()
+
+	err := server.Client().Downtime.All("unknown", time.Time{}, func(d updown.Downtime) bool {
+		return true
+	})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,134 @@
+package updown
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Errors matching an ErrorResponse with errors.Is, depending on its HTTP status code
+var (
+	// ErrNotFound is matched by 404 responses
+	ErrNotFound = errors.New("The requested resource does not exist")
+	// ErrUnauthorized is matched by 401 and 403 responses, when the API key is missing or invalid
+	ErrUnauthorized = errors.New("The API key is missing or invalid")
+	// ErrRateLimited is matched by 429 responses
+	ErrRateLimited = errors.New("Too many requests were sent to the API")
+	// ErrValidation is matched by 400 and 422 responses, when the API rejects the given parameters
+	ErrValidation = errors.New("The API rejected the given parameters")
+)
+
+// FieldError describes why a given field was rejected
+type FieldError struct {
+	// Name of the field, as sent to the API
+	Field string
+
+	// Reason why the field was rejected
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// Is makes a FieldError match ErrValidation
+func (e FieldError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// ValidationError lists the fields rejected before sending a request to the API
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Error()
+	}
+	return "Invalid parameters: " + strings.Join(messages, "; ")
+}
+
+// Is makes a ValidationError match ErrValidation
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// add records a rejected field
+func (e *ValidationError) add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// errorOrNil returns the validation error if a field was rejected, nil otherwise
+func (e *ValidationError) errorOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Is tells if the error matches one of ErrNotFound, ErrUnauthorized, ErrRateLimited or ErrValidation
+func (r *ErrorResponse) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return r.Response.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return r.Response.StatusCode == http.StatusUnauthorized || r.Response.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return r.Response.StatusCode == http.StatusTooManyRequests
+	case ErrValidation:
+		return isValidationStatus(r.Response.StatusCode)
+	}
+	return false
+}
+
+func isValidationStatus(code int) bool {
+	return code == http.StatusBadRequest || code == http.StatusUnprocessableEntity
+}
+
+// errorBody is the JSON body of an API error, apart from the message
+type errorBody struct {
+	// A single error message
+	Error string `json:"error"`
+	// Messages by field name, either a string or a list of strings
+	Errors map[string]json.RawMessage `json:"errors"`
+}
+
+// parseErrorDetails completes an ErrorResponse from the JSON body and the headers of the response
+func parseErrorDetails(r *ErrorResponse, data []byte) {
+	if wait, ok := parseRetryAfter(r.Response.Header.Get("Retry-After")); ok {
+		r.RetryAfter = wait
+	}
+
+	var body errorBody
+	if len(data) == 0 || json.Unmarshal(data, &body) != nil {
+		return
+	}
+
+	r.Detail = body.Error
+	// Other messages are kept out of Error, whose format is relied upon for missing resources
+	if r.Message == "" && isValidationStatus(r.Response.StatusCode) {
+		r.Message = body.Error
+	}
+
+	fields := make([]string, 0, len(body.Errors))
+	for field := range body.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		var messages []string
+		if err := json.Unmarshal(body.Errors[field], &messages); err != nil {
+			var message string
+			if json.Unmarshal(body.Errors[field], &message) != nil {
+				continue
+			}
+			messages = []string{message}
+		}
+		r.Fields = append(r.Fields, FieldError{Field: field, Message: strings.Join(messages, ", ")})
+	}
+}
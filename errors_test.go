@@ -0,0 +1,65 @@
+package updown
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newErrorResponse(status int, body string, header http.Header) error {
+	req, _ := http.NewRequest("PUT", "https://updown.io/api/checks/foo", nil)
+	if header == nil {
+		header = http.Header{}
+	}
+	return CheckResponse(&http.Response{
+		Request:    req,
+		StatusCode: status,
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	})
+}
+
+func TestErrorSentinels(t *testing.T) {
+	err := newErrorResponse(http.StatusNotFound, `{"error":"Not found"}`, nil)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.False(t, errors.Is(err, ErrValidation))
+	assert.Equal(t, "PUT https://updown.io/api/checks/foo: 404 ", err.Error())
+	var errResp *ErrorResponse
+	assert.True(t, errors.As(err, &errResp))
+	assert.Equal(t, "Not found", errResp.Detail)
+
+	// Bodies which are not JSON are ignored
+	err = newErrorResponse(http.StatusBadGateway, "<html><body>Bad Gateway</body></html>", nil)
+	assert.True(t, errors.As(err, &errResp))
+	assert.Equal(t, http.StatusBadGateway, errResp.Response.StatusCode)
+	err = newErrorResponse(http.StatusNotFound, "<html>Not Found</html>", nil)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	err = newErrorResponse(http.StatusUnauthorized, "", nil)
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+
+	err = newErrorResponse(http.StatusTooManyRequests, "", http.Header{"Retry-After": []string{"30"}})
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.True(t, errors.As(err, &errResp))
+	assert.Equal(t, 30*time.Second, errResp.RetryAfter)
+}
+
+func TestValidationErrorFields(t *testing.T) {
+	body := `{"error":"Validation failed","errors":{"url":["is invalid","is too long"],"period":"is not included in the list"}}`
+	err := newErrorResponse(http.StatusUnprocessableEntity, body, nil)
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	var errResp *ErrorResponse
+	assert.True(t, errors.As(err, &errResp))
+	assert.Equal(t, "Validation failed", errResp.Message)
+	assert.Equal(t, []FieldError{
+		{Field: "period", Message: "is not included in the list"},
+		{Field: "url", Message: "is invalid, is too long"},
+	}, errResp.Fields)
+	assert.True(t, errors.Is(errResp.Fields[0], ErrValidation))
+}
@@ -0,0 +1,181 @@
+package updown
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// EventType is the kind of event sent by Updown to webhooks
+type EventType string
+
+// Events sent by Updown to webhooks
+const (
+	EventCheckDown            EventType = "check.down"
+	EventCheckUp              EventType = "check.up"
+	EventCheckSSLInvalid      EventType = "check.ssl_invalid"
+	EventCheckSSLValid        EventType = "check.ssl_valid"
+	EventCheckSSLExpiration   EventType = "check.ssl_expiration"
+	EventCheckPerformanceDrop EventType = "check.performance_drop"
+)
+
+// SSLCertificate describes the SSL certificate of a check
+type SSLCertificate struct {
+	Subject   string `json:"subject,omitempty"`
+	Issuer    string `json:"issuer,omitempty"`
+	From      Time   `json:"from,omitempty"`
+	To        Time   `json:"to,omitempty"`
+	Algorithm string `json:"algorithm,omitempty"`
+}
+
+// EventSSL gives details about the SSL certificate, for SSL events
+type EventSSL struct {
+	Cert                 SSLCertificate `json:"cert,omitempty"`
+	Error                string         `json:"error,omitempty"`
+	DaysBeforeExpiration int            `json:"days_before_expiration,omitempty"`
+}
+
+// Event is an event sent by Updown to webhooks
+type Event struct {
+	Event       EventType `json:"event"`
+	Time        Time      `json:"time,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Check       Check     `json:"check,omitempty"`
+	// For check.down and check.up events
+	Downtime Downtime `json:"downtime,omitempty"`
+	// For SSL events
+	SSL *EventSSL `json:"ssl,omitempty"`
+	// For check.performance_drop events
+	ApdexDropped string  `json:"apdex_dropped,omitempty"`
+	LastMetrics  Metrics `json:"last_metrics,omitempty"`
+}
+
+// EventHandlerFunc handles an event received by a webhook. Returning an error makes the
+// webhook answer with an error, so that Updown sends the events again.
+type EventHandlerFunc func(Event) error
+
+// MaxWebhookBodySize is the maximum size in bytes of the body of a request received by a webhook
+const MaxWebhookBodySize = 1 << 20
+
+// ErrMethodNotAllowed indicates that a webhook received a request which is not a POST
+var ErrMethodNotAllowed = errors.New("Webhooks only accept POST requests")
+
+// WebhookHandler is an http.Handler receiving the events posted by Updown to webhooks,
+// and dispatching them to the handlers registered for their type. It is safe for concurrent use.
+type WebhookHandler struct {
+	mu       sync.RWMutex
+	handlers map[EventType][]EventHandlerFunc
+	any      []EventHandlerFunc
+	store    EventStore
+	// Stored events being delivered, by ID, closed once delivered
+	delivering map[string]chan struct{}
+}
+
+// NewWebhookHandler creates a webhook handler without any event handler
+func NewWebhookHandler() *WebhookHandler {
+	return &WebhookHandler{
+		handlers:   make(map[EventType][]EventHandlerFunc),
+		delivering: make(map[string]chan struct{}),
+	}
+}
+
+// On registers a handler for events of the given type
+func (h *WebhookHandler) On(event EventType, fn EventHandlerFunc) {
+	h.mu.Lock()
+	h.handlers[event] = append(h.handlers[event], fn)
+	h.mu.Unlock()
+}
+
+// OnAny registers a handler for all events, including the ones of unknown types
+func (h *WebhookHandler) OnAny(fn EventHandlerFunc) {
+	h.mu.Lock()
+	h.any = append(h.any, fn)
+	h.mu.Unlock()
+}
+
+// Dispatch calls the handlers registered for an event, in registration order, the handlers
+// for all events coming last. It stops at the first error.
+func (h *WebhookHandler) Dispatch(event Event) error {
+	h.mu.RLock()
+	handlers := append(append([]EventHandlerFunc{}, h.handlers[event.Event]...), h.any...)
+	h.mu.RUnlock()
+
+	for _, fn := range handlers {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP parses the events of a request and dispatches them. It answers with a 400 status
+// code when the payload is invalid or larger than MaxWebhookBodySize, and with a 500 status
+// code when a handler fails or when an event cannot be stored.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	events, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.receive(events); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// parseRequest parses the events of a POST request, reading at most MaxWebhookBodySize bytes.
+// It answers with an error and returns false when the request is invalid.
+func parseRequest(w http.ResponseWriter, r *http.Request) ([]Event, bool) {
+	if r.Method != "POST" {
+		http.Error(w, ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	events, err := ParseEvents(http.MaxBytesReader(w, r.Body, MaxWebhookBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return events, true
+}
+
+// ParseEvents parses the events posted by Updown to a webhook, either a list of events or a single event
+func ParseEvents(r io.Reader) ([]Event, error) {
+	reader := bufio.NewReader(r)
+	first, err := peekNonSpace(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	if first == '{' {
+		var event Event
+		err = json.NewDecoder(reader).Decode(&event)
+		events = []Event{event}
+	} else {
+		err = json.NewDecoder(reader).Decode(&events)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// peekNonSpace gives the first byte which is not a whitespace, without consuming it
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, r.UnreadByte()
+	}
+}
@@ -0,0 +1,112 @@
+package updown
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const webhookPayload = `[
+  {
+    "event": "check.down",
+    "time": "2016-02-07T13:59:51Z",
+    "description": "Google is DOWN since 2016-02-07 13:59:51 UTC",
+    "check": {"token": "ngg8", "url": "https://google.fr", "alias": "Google", "down": true, "down_since": "2016-02-07T13:59:51Z"},
+    "downtime": {"id": "56b74bbf8f0b8e3f77000016", "error": "Connection refused", "started_at": "2016-02-07T13:59:51Z", "ended_at": null, "duration": null}
+  },
+  {
+    "event": "check.ssl_expiration",
+    "time": "2016-02-08T10:00:00Z",
+    "check": {"token": "ngg8", "url": "https://google.fr"},
+    "ssl": {"cert": {"subject": "google.fr", "to": "2016-02-15T10:00:00Z"}, "days_before_expiration": 7}
+  },
+  {
+    "event": "check.unknown",
+    "check": {"token": "ngg8"}
+  }
+]`
+
+func postWebhook(handler http.Handler, method, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, "/webhook", strings.NewReader(body)))
+	return recorder
+}
+
+func TestParseEvents(t *testing.T) {
+	events, err := ParseEvents(strings.NewReader(webhookPayload))
+	assert.Nil(t, err)
+	assert.Len(t, events, 3)
+
+	down := events[0]
+	assert.Equal(t, EventCheckDown, down.Event)
+	assert.Equal(t, time.Date(2016, 2, 7, 13, 59, 51, 0, time.UTC), down.Time.UTC())
+	assert.Equal(t, "ngg8", down.Check.Token)
+	assert.True(t, down.Check.Down)
+	assert.Equal(t, "56b74bbf8f0b8e3f77000016", down.Downtime.ID)
+	assert.Equal(t, "Connection refused", down.Downtime.Error)
+	assert.True(t, down.Downtime.EndedAt.IsZero())
+	assert.Nil(t, down.SSL)
+
+	ssl := events[1]
+	assert.Equal(t, EventCheckSSLExpiration, ssl.Event)
+	assert.Equal(t, 7, ssl.SSL.DaysBeforeExpiration)
+	assert.Equal(t, "google.fr", ssl.SSL.Cert.Subject)
+
+	events, err = ParseEvents(strings.NewReader(` {"event": "check.up", "check": {"token": "ngg8"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, EventCheckUp, events[0].Event)
+
+	_, err = ParseEvents(strings.NewReader(`[{"event": `))
+	assert.NotNil(t, err)
+	_, err = ParseEvents(strings.NewReader(""))
+	assert.NotNil(t, err)
+}
+
+func TestWebhookHandlerDispatch(t *testing.T) {
+	handler := NewWebhookHandler()
+	var down, ssl, all []string
+	handler.On(EventCheckDown, func(e Event) error {
+		down = append(down, e.Check.Token)
+		return nil
+	})
+	handler.On(EventCheckSSLExpiration, func(e Event) error {
+		ssl = append(ssl, e.Check.Token)
+		return nil
+	})
+	handler.OnAny(func(e Event) error {
+		all = append(all, string(e.Event))
+		return nil
+	})
+
+	recorder := postWebhook(handler, "POST", webhookPayload)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, []string{"ngg8"}, down)
+	assert.Equal(t, []string{"ngg8"}, ssl)
+	assert.Equal(t, []string{"check.down", "check.ssl_expiration", "check.unknown"}, all)
+}
+
+func TestWebhookHandlerErrors(t *testing.T) {
+	handler := NewWebhookHandler()
+	handler.On(EventCheckDown, func(e Event) error {
+		return errors.New("database is down")
+	})
+
+	recorder := postWebhook(handler, "GET", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	recorder = postWebhook(handler, "POST", "not json")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// Large bodies are not read entirely
+	recorder = postWebhook(handler, "POST", "["+strings.Repeat(" ", MaxWebhookBodySize)+"]")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = postWebhook(handler, "POST", webhookPayload)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "database is down")
+}
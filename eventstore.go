@@ -0,0 +1,404 @@
+package updown
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrNoEventStore indicates that a webhook handler does not store the events it receives
+var ErrNoEventStore = errors.New("The webhook handler has no event store")
+
+// ID identifies an event. Updown sends the same event again when a webhook fails, the copies
+// share the same ID. Events of a downtime are identified by its ID, other events by a hash of
+// their type, check token, time, description, SSL details and APDEX drop.
+func (e Event) ID() string {
+	if e.Downtime.ID != "" {
+		return fmt.Sprintf("%s/%s", e.Event, e.Downtime.ID)
+	}
+
+	// The state of the check is left out, it may be more recent in copies of the event
+	data, _ := json.Marshal(struct {
+		Event        EventType
+		Token        string
+		Time         Time
+		Description  string
+		SSL          *EventSSL
+		ApdexDropped string
+	}{e.Event, e.Check.Token, e.Time, e.Description, e.SSL, e.ApdexDropped})
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%s/%s/%s", e.Event, e.Check.Token, hex.EncodeToString(sum[:16]))
+}
+
+// EventStore is a durable log of the events received by a webhook
+type EventStore interface {
+	// Append stores an event. It returns false when an event with the same ID is already stored.
+	Append(event Event) (bool, error)
+	// Ack records that an event was delivered to all its handlers
+	Ack(id string) error
+	// Acked tells if an event was delivered to all its handlers
+	Acked(id string) (bool, error)
+	// Pending lists the events not delivered yet, in the order they were received
+	Pending() ([]Event, error)
+	// Range lists the events which happened in [from, to), in the order they were received
+	Range(from, to time.Time) ([]Event, error)
+}
+
+// NewDurableWebhookHandler creates a webhook handler storing the events it receives before
+// dispatching them. An event is acknowledged in the store once all its handlers succeeded, and
+// copies of acknowledged events sent again by Updown are not dispatched, nor copies received while
+// the event is being dispatched. Events are delivered at
+// least once: call Redeliver when starting to deliver the events received before a crash.
+func NewDurableWebhookHandler(store EventStore) *WebhookHandler {
+	h := NewWebhookHandler()
+	h.store = store
+	return h
+}
+
+// Redeliver dispatches the stored events which were not delivered yet, in the order they were
+// received, and gives the number of events delivered. It stops at the first error.
+func (h *WebhookHandler) Redeliver() (int, error) {
+	if h.store == nil {
+		return 0, ErrNoEventStore
+	}
+	events, err := h.store.Pending()
+	if err != nil {
+		return 0, err
+	}
+	for i, event := range events {
+		if err := h.deliver(event); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+
+// Replay dispatches again the stored events which happened in [from, to), in the order they were
+// received, whether they were already delivered or not. It gives the number of events dispatched
+// and stops at the first error.
+func (h *WebhookHandler) Replay(from, to time.Time) (int, error) {
+	if h.store == nil {
+		return 0, ErrNoEventStore
+	}
+	events, err := h.store.Range(from, to)
+	if err != nil {
+		return 0, err
+	}
+	for i, event := range events {
+		if err := h.Dispatch(event); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+
+// receive dispatches the events received by the webhook. A durable handler stores all of them
+// before dispatching the ones not acknowledged yet.
+func (h *WebhookHandler) receive(events []Event) error {
+	if h.store == nil {
+		for _, event := range events {
+			if err := h.Dispatch(event); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, event := range events {
+		if _, err := h.store.Append(event); err != nil {
+			return err
+		}
+	}
+	for _, event := range events {
+		if err := h.deliver(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver dispatches a stored event and acknowledges it, unless it is already acknowledged.
+// Copies of an event received while it is being delivered wait for the delivery, and are
+// delivered only if it fails.
+func (h *WebhookHandler) deliver(event Event) error {
+	id := event.ID()
+	for {
+		acked, err := h.store.Acked(id)
+		if err != nil || acked {
+			return err
+		}
+
+		h.mu.Lock()
+		done, busy := h.delivering[id]
+		if !busy {
+			done = make(chan struct{})
+			h.delivering[id] = done
+		}
+		h.mu.Unlock()
+		if busy {
+			<-done
+			continue
+		}
+
+		err = h.Dispatch(event)
+		if err == nil {
+			err = h.store.Ack(id)
+		}
+
+		h.mu.Lock()
+		delete(h.delivering, id)
+		h.mu.Unlock()
+		close(done)
+		return err
+	}
+}
+
+// eventRecord is a line of a FileEventStore, either a received event or its acknowledgement
+type eventRecord struct {
+	ID         string     `json:"id"`
+	Event      *Event     `json:"event,omitempty"`
+	ReceivedAt *time.Time `json:"received_at,omitempty"`
+	Ack        bool       `json:"ack,omitempty"`
+}
+
+// storedEvent is an event held by a FileEventStore
+type storedEvent struct {
+	id         string
+	event      Event
+	receivedAt time.Time
+}
+
+// FileEventStore is an EventStore appending events to a file, one JSON record per line.
+// Every record is synced to disk before returning. The file must not be shared by processes.
+// All the events are also kept in memory: the log grows with every event received, until
+// Compact removes the old ones.
+type FileEventStore struct {
+	mu     sync.Mutex
+	file   *os.File
+	events []storedEvent
+	index  map[string]int
+	acked  map[string]bool
+	now    func() time.Time
+}
+
+// OpenFileEventStore opens the event log at the given path, creating it and its directories if needed.
+// A record partially written when the process stopped is discarded.
+func OpenFileEventStore(path string) (*FileEventStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	file, err := openEventLog(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileEventStore{
+		file:  file,
+		index: make(map[string]int),
+		acked: make(map[string]bool),
+		now:   time.Now,
+	}
+	if err := s.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Append stores an event. It returns false when an event with the same ID is already stored.
+func (s *FileEventStore) Append(event Event) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := event.ID()
+	if _, found := s.index[id]; found {
+		return false, nil
+	}
+	now := s.now()
+	if err := s.write(eventRecord{ID: id, Event: &event, ReceivedAt: &now}); err != nil {
+		return false, err
+	}
+	s.index[id] = len(s.events)
+	s.events = append(s.events, storedEvent{id: id, event: event, receivedAt: now})
+	return true, nil
+}
+
+// Ack records that an event was delivered to all its handlers
+func (s *FileEventStore) Ack(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.index[id]; !found {
+		return fmt.Errorf("Unknown event %s", id)
+	}
+	if s.acked[id] {
+		return nil
+	}
+	if err := s.write(eventRecord{ID: id, Ack: true}); err != nil {
+		return err
+	}
+	s.acked[id] = true
+	return nil
+}
+
+// Acked tells if an event was delivered to all its handlers
+func (s *FileEventStore) Acked(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.acked[id], nil
+}
+
+// Pending lists the events not delivered yet, in the order they were received
+func (s *FileEventStore) Pending() ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []Event
+	for _, stored := range s.events {
+		if !s.acked[stored.id] {
+			pending = append(pending, stored.event)
+		}
+	}
+	return pending, nil
+}
+
+// Range lists the events which happened in [from, to), in the order they were received
+func (s *FileEventStore) Range(from, to time.Time) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []Event
+	for _, stored := range s.events {
+		if !stored.event.Time.Before(from) && stored.event.Time.Before(to) {
+			events = append(events, stored.event)
+		}
+	}
+	return events, nil
+}
+
+// Compact rewrites the log without the acknowledged events which happened before the given time,
+// and removes them from memory. They cannot be replayed anymore, and copies of them sent again
+// by Updown are delivered again. The log is replaced atomically.
+func (s *FileEventStore) Compact(before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var kept []storedEvent
+	var buf bytes.Buffer
+	for _, stored := range s.events {
+		acked := s.acked[stored.id]
+		if acked && stored.event.Time.Before(before) {
+			continue
+		}
+		kept = append(kept, stored)
+
+		event, receivedAt := stored.event, stored.receivedAt
+		records := []eventRecord{{ID: stored.id, Event: &event, ReceivedAt: &receivedAt}}
+		if acked {
+			records = append(records, eventRecord{ID: stored.id, Ack: true})
+		}
+		for _, record := range records {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			buf.Write(append(data, '\n'))
+		}
+	}
+
+	path := s.file.Name()
+	if err := replaceFile(path, buf.Bytes()); err != nil {
+		return err
+	}
+	file, err := openEventLog(path)
+	if err != nil {
+		return err
+	}
+	s.file.Close()
+	s.file = file
+
+	s.events = kept
+	s.index = make(map[string]int, len(kept))
+	acked := make(map[string]bool)
+	for i, stored := range kept {
+		s.index[stored.id] = i
+		if s.acked[stored.id] {
+			acked[stored.id] = true
+		}
+	}
+	s.acked = acked
+	return nil
+}
+
+// Close closes the file of the log
+func (s *FileEventStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+// load reads the records of the file, and truncates a last record missing its end of line
+func (s *FileEventStore) load() error {
+	data, err := ioutil.ReadAll(s.file)
+	if err != nil {
+		return err
+	}
+
+	if end := bytes.LastIndexByte(data, '\n') + 1; end < len(data) {
+		if err := s.file.Truncate(int64(end)); err != nil {
+			return err
+		}
+		data = data[:end]
+	}
+
+	for i, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+		var record eventRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("Invalid record on line %d of %s: %w", i+1, s.file.Name(), err)
+		}
+		switch {
+		case record.Ack:
+			s.acked[record.ID] = true
+		case record.Event != nil:
+			if _, found := s.index[record.ID]; !found {
+				stored := storedEvent{id: record.ID, event: *record.Event}
+				if record.ReceivedAt != nil {
+					stored.receivedAt = *record.ReceivedAt
+				}
+				s.index[record.ID] = len(s.events)
+				s.events = append(s.events, stored)
+			}
+		}
+	}
+	return nil
+}
+
+// write appends a record to the file and syncs it
+func (s *FileEventStore) write(record eventRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// openEventLog opens a log file for appending records
+func openEventLog(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+}
@@ -0,0 +1,240 @@
+package updown
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newEvent(event EventType, token string, at time.Time) Event {
+	return Event{Event: event, Check: Check{Token: token}, Time: Time{at}}
+}
+
+func TestEventID(t *testing.T) {
+	events, err := ParseEvents(strings.NewReader(webhookPayload))
+	assert.Nil(t, err)
+	assert.Equal(t, "check.down/56b74bbf8f0b8e3f77000016", events[0].ID())
+
+	// Copies share the same ID, even with a more recent state of the check
+	again := events[1]
+	again.Check.Alias = "Google"
+	assert.Equal(t, events[1].ID(), again.ID())
+
+	// Events without a time, or at the same instant, are told apart
+	at := time.Date(2016, 2, 7, 13, 0, 0, 0, time.UTC)
+	a := newEvent(EventCheckPerformanceDrop, "ngg8", at)
+	b := a
+	b.ApdexDropped = "0.9 to 0.7"
+	assert.NotEqual(t, a.ID(), b.ID())
+	assert.NotEqual(t, events[2].ID(), newEvent("check.unknown", "other", time.Time{}).ID())
+}
+
+func TestFileEventStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "updown", "events.jsonl")
+	store, err := OpenFileEventStore(path)
+	assert.Nil(t, err)
+
+	start := time.Date(2016, 2, 7, 13, 0, 0, 0, time.UTC)
+	down := newEvent(EventCheckDown, "ngg8", start)
+	up := newEvent(EventCheckUp, "ngg8", start.Add(time.Hour))
+
+	stored, err := store.Append(down)
+	assert.Nil(t, err)
+	assert.True(t, stored)
+	stored, err = store.Append(down)
+	assert.Nil(t, err)
+	assert.False(t, stored)
+	store.Append(up)
+
+	assert.Nil(t, store.Ack(down.ID()))
+	assert.Nil(t, store.Ack(down.ID()))
+	assert.NotNil(t, store.Ack("unknown"))
+	acked, _ := store.Acked(down.ID())
+	assert.True(t, acked)
+
+	pending, _ := store.Pending()
+	assert.Equal(t, []string{up.ID()}, eventIDs(pending))
+	events, _ := store.Range(start, start.Add(time.Hour))
+	assert.Equal(t, []string{down.ID()}, eventIDs(events))
+	assert.Nil(t, store.Close())
+
+	// A record partially written by a crash is discarded
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	f.Write([]byte(`{"id":"check.up/ngg8`))
+	f.Close()
+
+	store, err = OpenFileEventStore(path)
+	assert.Nil(t, err)
+	defer store.Close()
+	pending, _ = store.Pending()
+	assert.Equal(t, []string{up.ID()}, eventIDs(pending))
+	stored, _ = store.Append(down)
+	assert.False(t, stored)
+	assert.Nil(t, store.Ack(up.ID()))
+	pending, _ = store.Pending()
+	assert.Empty(t, pending)
+}
+
+func TestFileEventStoreCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	store, _ := OpenFileEventStore(path)
+
+	start := time.Date(2016, 2, 7, 13, 0, 0, 0, time.UTC)
+	old := newEvent(EventCheckDown, "ngg8", start)
+	pending := newEvent(EventCheckDown, "abcd", start)
+	recent := newEvent(EventCheckUp, "ngg8", start.Add(time.Hour))
+	for _, event := range []Event{old, pending, recent} {
+		store.Append(event)
+	}
+	store.Ack(old.ID())
+	store.Ack(recent.ID())
+
+	// Only the old acknowledged event is removed
+	assert.Nil(t, store.Compact(start.Add(time.Minute)))
+	events, _ := store.Range(time.Time{}, start.Add(24*time.Hour))
+	assert.Equal(t, []string{pending.ID(), recent.ID()}, eventIDs(events))
+
+	// The store is still usable, and the log was rewritten
+	stored, err := store.Append(old)
+	assert.Nil(t, err)
+	assert.True(t, stored)
+	store.Close()
+
+	store, err = OpenFileEventStore(path)
+	assert.Nil(t, err)
+	defer store.Close()
+	events, _ = store.Pending()
+	assert.Equal(t, []string{pending.ID(), old.ID()}, eventIDs(events))
+	acked, _ := store.Acked(recent.ID())
+	assert.True(t, acked)
+}
+
+func TestFileEventStoreInvalidRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	ioutil.WriteFile(path, []byte("not json\n"), 0600)
+
+	_, err := OpenFileEventStore(path)
+	assert.NotNil(t, err)
+}
+
+func TestDurableWebhookHandler(t *testing.T) {
+	store, _ := OpenFileEventStore(filepath.Join(t.TempDir(), "events.jsonl"))
+	defer store.Close()
+
+	handler := NewDurableWebhookHandler(store)
+	failing := true
+	var delivered []string
+	handler.OnAny(func(e Event) error {
+		if failing {
+			return errors.New("database is down")
+		}
+		delivered = append(delivered, e.ID())
+		return nil
+	})
+
+	// The event is stored even when a handler fails
+	recorder := postWebhook(handler, "POST", webhookPayload)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	pending, _ := store.Pending()
+	assert.Len(t, pending, 3)
+
+	// Updown sends the events again
+	failing = false
+	recorder = postWebhook(handler, "POST", webhookPayload)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, delivered, 3)
+	pending, _ = store.Pending()
+	assert.Empty(t, pending)
+
+	// Acknowledged events are not dispatched again
+	recorder = postWebhook(handler, "POST", webhookPayload)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, delivered, 3)
+
+	// Replaying dispatches the events of the range, even acknowledged
+	delivered = nil
+	from := time.Date(2016, 2, 7, 0, 0, 0, 0, time.UTC)
+	n, err := handler.Replay(from, from.Add(24*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"check.down/56b74bbf8f0b8e3f77000016"}, delivered)
+}
+
+func TestDurableWebhookHandlerConcurrentCopies(t *testing.T) {
+	store, _ := OpenFileEventStore(filepath.Join(t.TempDir(), "events.jsonl"))
+	defer store.Close()
+
+	handler := NewDurableWebhookHandler(store)
+	started, release := make(chan struct{}), make(chan struct{})
+	var dispatched int32
+	handler.OnAny(func(e Event) error {
+		if atomic.AddInt32(&dispatched, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return nil
+	})
+
+	// Updown sends the event again while the first copy is being dispatched
+	body := `{"event": "check.down", "check": {"token": "ngg8"}, "downtime": {"id": "56b74bbf8f0b8e3f77000016"}}`
+	codes := make(chan int)
+	go func() {
+		codes <- postWebhook(handler, "POST", body).Code
+	}()
+	<-started
+	go func() {
+		codes <- postWebhook(handler, "POST", body).Code
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	assert.Equal(t, http.StatusOK, <-codes)
+	assert.Equal(t, http.StatusOK, <-codes)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&dispatched))
+}
+
+func TestWebhookHandlerRedeliver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	store, _ := OpenFileEventStore(path)
+	store.Append(newEvent(EventCheckDown, "ngg8", time.Now()))
+	store.Close()
+
+	// After a restart
+	store, _ = OpenFileEventStore(path)
+	defer store.Close()
+	handler := NewDurableWebhookHandler(store)
+	var delivered int
+	handler.On(EventCheckDown, func(e Event) error {
+		delivered++
+		return nil
+	})
+
+	n, err := handler.Redeliver()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, n)
+	n, err = handler.Redeliver()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 1, delivered)
+
+	_, err = NewWebhookHandler().Redeliver()
+	assert.Equal(t, ErrNoEventStore, err)
+	_, err = NewWebhookHandler().Replay(time.Time{}, time.Now())
+	assert.Equal(t, ErrNoEventStore, err)
+}
+
+func eventIDs(events []Event) []string {
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.ID()
+	}
+	return ids
+}
@@ -0,0 +1,235 @@
+package updown
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// fileCacheEntry is a value stored in a FileCache, a zero expiry never expires
+type fileCacheEntry struct {
+	Value     string    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (e fileCacheEntry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// FileCache is a cache persisted to a JSON file, so that values survive between runs and are shared
+// by processes using the same file. Writes are atomic and the file is locked while it is used.
+// Errors reading or writing the file are not fatal: values are then reported as missing, and the
+// last error is available from Err.
+type FileCache struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+
+	// mu serializes accesses from the same process, the file lock is per process on some systems
+	mu  sync.Mutex
+	err error
+}
+
+// DefaultFileCachePath gives the path of the cache file in the cache directory of the user
+func DefaultFileCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "updown", "cache.json"), nil
+}
+
+// NewFileCache creates a cache persisted to the file at the given path, where values expire after
+// the given duration by default. A duration of zero or less never expires. Missing directories are
+// created when writing the file.
+func NewFileCache(path string, ttl time.Duration) *FileCache {
+	return &FileCache{path: path, ttl: ttl, now: time.Now}
+}
+
+// Has determines if we can find in the cache a key for the given value
+func (c *FileCache) Has(key string) bool {
+	has, _ := c.Get(key)
+	return has
+}
+
+// Put associates a key to a given value in the cache, for the default duration of the cache
+func (c *FileCache) Put(key, value string) {
+	c.PutWithTTL(key, value, 0)
+}
+
+// PutWithTTL associates a key to a given value in the cache, for the given duration.
+// A duration of zero or less uses the default duration of the cache.
+func (c *FileCache) PutWithTTL(key, value string, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+
+	entry := fileCacheEntry{Value: value}
+	if ttl > 0 {
+		entry.ExpiresAt = c.now().Add(ttl)
+	}
+
+	c.update(func(items map[string]fileCacheEntry) {
+		items[key] = entry
+	})
+}
+
+// PutMany associates keys to values in the cache, for the given duration, writing the file once.
+// A duration of zero or less uses the default duration of the cache.
+func (c *FileCache) PutMany(values map[string]string, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+
+	c.update(func(items map[string]fileCacheEntry) {
+		for key, value := range values {
+			items[key] = fileCacheEntry{Value: value, ExpiresAt: expiresAt}
+		}
+	})
+}
+
+// Get gets a value from the cache by its key and tells if it was found or not
+func (c *FileCache) Get(key string) (has bool, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	unlock, err := lockFile(c.path+".lock", false)
+	if err != nil {
+		c.err = err
+		return false, ""
+	}
+	defer unlock()
+
+	items, err := c.read()
+	if err != nil {
+		c.err = err
+		return false, ""
+	}
+
+	entry, has := items[key]
+	if !has || entry.expired(c.now()) {
+		return false, ""
+	}
+	return true, entry.Value
+}
+
+// Delete removes a key from the cache
+func (c *FileCache) Delete(key string) {
+	c.update(func(items map[string]fileCacheEntry) {
+		delete(items, key)
+	})
+}
+
+// Clear removes all keys from the cache
+func (c *FileCache) Clear() {
+	c.update(func(items map[string]fileCacheEntry) {
+		for key := range items {
+			delete(items, key)
+		}
+	})
+}
+
+// Purge removes all expired keys from the cache
+func (c *FileCache) Purge() {
+	now := c.now()
+	c.update(func(items map[string]fileCacheEntry) {
+		for key, entry := range items {
+			if entry.expired(now) {
+				delete(items, key)
+			}
+		}
+	})
+}
+
+// Err returns the last error encountered while reading or writing the file
+func (c *FileCache) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+// update applies changes to the values of the cache and writes them back, holding an exclusive lock
+func (c *FileCache) update(change func(map[string]fileCacheEntry)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		c.err = err
+		return
+	}
+
+	unlock, err := lockFile(c.path+".lock", true)
+	if err != nil {
+		c.err = err
+		return
+	}
+	defer unlock()
+
+	items, err := c.read()
+	if err != nil {
+		// Start over from a corrupted file
+		items = make(map[string]fileCacheEntry)
+	}
+	change(items)
+
+	if err := c.write(items); err != nil {
+		c.err = err
+	}
+}
+
+// read reads the values from the file, a missing file is an empty cache
+func (c *FileCache) read() (map[string]fileCacheEntry, error) {
+	items := make(map[string]fileCacheEntry)
+
+	data, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return items, nil
+	}
+	if err != nil {
+		return items, err
+	}
+
+	if err := json.Unmarshal(data, &items); err != nil {
+		return make(map[string]fileCacheEntry), err
+	}
+	return items, nil
+}
+
+// write replaces the file atomically
+func (c *FileCache) write(items map[string]fileCacheEntry) error {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return replaceFile(c.path, data)
+}
+
+// replaceFile replaces a file atomically, by renaming a temporary file written next to it
+func replaceFile(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,128 @@
+package updown
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "updown", "cache.json")
+	c := NewFileCache(path, 0)
+
+	assert.False(t, c.Has("foo"))
+	c.Put("foo", "bar")
+	c.Put("baz", "qux")
+	assert.Nil(t, c.Err())
+
+	// Values are persisted
+	other := NewFileCache(path, 0)
+	has, val := other.Get("foo")
+	assert.True(t, has)
+	assert.Equal(t, "bar", val)
+
+	other.Delete("foo")
+	assert.False(t, c.Has("foo"))
+	assert.True(t, c.Has("baz"))
+
+	c.Clear()
+	assert.False(t, other.Has("baz"))
+}
+
+func TestFileCacheTTL(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2016, 4, 15, 0, 0, 0, 0, time.UTC)}
+	c := NewFileCache(filepath.Join(t.TempDir(), "cache.json"), time.Minute)
+	c.now = clock.Now
+
+	c.Put("default", "a")
+	c.PutWithTTL("long", "b", time.Hour)
+
+	clock.Advance(time.Minute)
+	assert.False(t, c.Has("default"))
+	assert.True(t, c.Has("long"))
+
+	c.Purge()
+	items, _ := c.read()
+	assert.Len(t, items, 1)
+}
+
+func TestFileCachePutMany(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2016, 4, 15, 0, 0, 0, 0, time.UTC)}
+	c := NewFileCache(filepath.Join(t.TempDir(), "cache.json"), 0)
+	c.now = clock.Now
+
+	values := make(map[string]string)
+	for i := 0; i < 300; i++ {
+		values[fmt.Sprintf("check%d", i)] = fmt.Sprintf("token%d", i)
+	}
+	c.PutMany(values, time.Minute)
+	assert.Nil(t, c.Err())
+
+	items, _ := c.read()
+	assert.Len(t, items, 300)
+	has, val := c.Get("check42")
+	assert.True(t, has)
+	assert.Equal(t, "token42", val)
+
+	clock.Advance(time.Minute)
+	assert.False(t, c.Has("check42"))
+}
+
+// countingCache counts the writes to a FileCache
+type countingCache struct {
+	*FileCache
+	puts, batches int
+}
+
+func (c *countingCache) PutWithTTL(key, value string, ttl time.Duration) {
+	c.puts++
+	c.FileCache.PutWithTTL(key, value, ttl)
+}
+
+func (c *countingCache) PutMany(values map[string]string, ttl time.Duration) {
+	c.batches++
+	c.FileCache.PutMany(values, ttl)
+}
+
+func TestTokenForAliasWritesFileCacheOnce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"token":"a","alias":"foo"},{"token":"b","alias":"bar"},{"token":"c","alias":"baz"}]`))
+	}))
+	defer server.Close()
+
+	cache := &countingCache{FileCache: NewFileCache(filepath.Join(t.TempDir(), "cache.json"), 0)}
+	client := NewClient("key", nil, WithCache(cache))
+	client.BaseURL, _ = url.Parse(server.URL + "/api/")
+
+	token, err := client.Check.TokenForAlias("bar")
+	assert.Nil(t, err)
+	assert.Equal(t, "b", token)
+	assert.Equal(t, 1, cache.batches)
+	assert.Equal(t, 0, cache.puts)
+}
+
+func TestFileCacheConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// One cache per writer, as separate processes would do
+			NewFileCache(path, 0).Put(fmt.Sprintf("key%d", i), "value")
+		}(i)
+	}
+	wg.Wait()
+
+	items, err := NewFileCache(path, 0).read()
+	assert.Nil(t, err)
+	assert.Len(t, items, 10)
+}
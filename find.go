@@ -0,0 +1,128 @@
+package updown
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ErrAmbiguousMatch indicates that several checks match when a single one is expected
+var ErrAmbiguousMatch = errors.New("Several checks match the given filter")
+
+// CheckFilter selects checks by their attributes. Zero fields are ignored,
+// a check must match all the other ones.
+type CheckFilter struct {
+	// Exact URL of the check
+	URL string
+	// Host of the URL of the check, compared case-insensitively and ignoring a "www." prefix
+	Host string
+	// Shell pattern matched against the whole alias, see path.Match
+	AliasGlob string
+	// Regular expression matched against the alias
+	AliasRegexp *regexp.Regexp
+	// Interval in seconds
+	Period int
+	// Is the check down
+	Down *bool
+	// Is the check enabled
+	Enabled *bool
+	// Is the status page public
+	Published *bool
+	// Is the SSL certificate valid
+	SSLValid *bool
+}
+
+// Match tells if a check matches the filter
+func (f CheckFilter) Match(check Check) bool {
+	if f.URL != "" && check.URL != f.URL {
+		return false
+	}
+	if f.Host != "" && normalizeHost(hostOf(check.URL)) != normalizeHost(f.Host) {
+		return false
+	}
+	if f.AliasGlob != "" {
+		if matched, err := path.Match(f.AliasGlob, check.Alias); err != nil || !matched {
+			return false
+		}
+	}
+	if f.AliasRegexp != nil && !f.AliasRegexp.MatchString(check.Alias) {
+		return false
+	}
+	if f.Period != 0 && check.Period != f.Period {
+		return false
+	}
+	return matchBool(f.Down, check.Down) &&
+		matchBool(f.Enabled, check.Enabled) &&
+		matchBool(f.Published, check.Published) &&
+		matchBool(f.SSLValid, check.SSL.Valid)
+}
+
+// Find lists the checks matching a filter
+func (s *CheckService) Find(filter CheckFilter) ([]Check, *http.Response, error) {
+	return s.FindContext(context.Background(), filter)
+}
+
+// FindContext is like Find but takes a context for the request
+func (s *CheckService) FindContext(ctx context.Context, filter CheckFilter) ([]Check, *http.Response, error) {
+	if filter.AliasGlob != "" {
+		if _, err := path.Match(filter.AliasGlob, ""); err != nil {
+			return nil, nil, &ValidationError{Fields: []FieldError{{Field: "alias", Message: "is not a valid pattern"}}}
+		}
+	}
+
+	checks, resp, err := s.ListContext(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var res []Check
+	for _, check := range checks {
+		if filter.Match(check) {
+			res = append(res, check)
+		}
+	}
+	return res, resp, nil
+}
+
+// FindOne finds the single check matching a filter. It returns ErrTokenNotFound when
+// no check matches, and ErrAmbiguousMatch when several checks match.
+func (s *CheckService) FindOne(filter CheckFilter) (Check, *http.Response, error) {
+	return s.FindOneContext(context.Background(), filter)
+}
+
+// FindOneContext is like FindOne but takes a context for the request
+func (s *CheckService) FindOneContext(ctx context.Context, filter CheckFilter) (Check, *http.Response, error) {
+	checks, resp, err := s.FindContext(ctx, filter)
+	if err != nil {
+		return Check{}, resp, err
+	}
+
+	switch len(checks) {
+	case 0:
+		return Check{}, resp, ErrTokenNotFound
+	case 1:
+		return checks[0], resp, nil
+	}
+	return Check{}, resp, ErrAmbiguousMatch
+}
+
+func matchBool(expected *bool, value bool) bool {
+	return expected == nil || *expected == value
+}
+
+// hostOf gives the host of a URL, without its port
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSuffix(host, ".")), "www.")
+}
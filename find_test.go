@@ -0,0 +1,70 @@
+package updown_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/antoineaugusti/updown"
+	"github.com/stretchr/testify/assert"
+)
+
+var findChecks = []updown.Check{
+	{Token: "a", URL: "https://www.google.fr/", Alias: "Google FR", Enabled: true, SSL: updown.SSL{Valid: true}},
+	{Token: "b", URL: "https://google.com", Alias: "Google COM", Enabled: true, Down: true, Period: 300},
+	{Token: "c", URL: "http://example.com:8080/health", Alias: "Example", Published: true},
+}
+
+func tokens(checks []updown.Check) []string {
+	res := []string{}
+	for _, check := range checks {
+		res = append(res, check.Token)
+	}
+	return res
+}
+
+func TestFind(t *testing.T) {
+	server := newChecksServer(findChecks...)
+	defer server.Close()
+	client := server.Client()
+
+	for _, tc := range []struct {
+		filter   updown.CheckFilter
+		expected []string
+	}{
+		{updown.CheckFilter{}, []string{"a", "b", "c"}},
+		{updown.CheckFilter{URL: "https://google.com"}, []string{"b"}},
+		{updown.CheckFilter{Host: "GOOGLE.fr"}, []string{"a"}},
+		{updown.CheckFilter{Host: "example.com"}, []string{"c"}},
+		{updown.CheckFilter{AliasGlob: "Google *"}, []string{"a", "b"}},
+		{updown.CheckFilter{AliasRegexp: regexp.MustCompile(`(?i)^example$`)}, []string{"c"}},
+		{updown.CheckFilter{Down: updown.Bool(false), Enabled: updown.Bool(true)}, []string{"a"}},
+		{updown.CheckFilter{Published: updown.Bool(true)}, []string{"c"}},
+		{updown.CheckFilter{SSLValid: updown.Bool(true)}, []string{"a"}},
+		{updown.CheckFilter{Period: 300}, []string{"b"}},
+		{updown.CheckFilter{AliasGlob: "Google *", Period: 60}, []string{"a"}},
+	} {
+		checks, _, err := client.Check.Find(tc.filter)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, tokens(checks), "%+v", tc.filter)
+	}
+
+	_, _, err := client.Check.Find(updown.CheckFilter{AliasGlob: "["})
+	assert.True(t, errors.Is(err, updown.ErrValidation))
+}
+
+func TestFindOne(t *testing.T) {
+	server := newChecksServer(findChecks...)
+	defer server.Close()
+	client := server.Client()
+
+	check, _, err := client.Check.FindOne(updown.CheckFilter{Host: "google.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, "b", check.Token)
+
+	_, _, err = client.Check.FindOne(updown.CheckFilter{AliasGlob: "Google*"})
+	assert.Equal(t, updown.ErrAmbiguousMatch, err)
+
+	_, _, err = client.Check.FindOne(updown.CheckFilter{Host: "bing.com"})
+	assert.Equal(t, updown.ErrTokenNotFound, err)
+}
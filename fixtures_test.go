@@ -0,0 +1,15 @@
+package updown_test
+
+import (
+	"github.com/antoineaugusti/updown"
+	"github.com/antoineaugusti/updown/updowntest"
+)
+
+// newChecksServer starts a fake API holding the given checks
+func newChecksServer(checks ...updown.Check) *updowntest.Server {
+	server := updowntest.NewServer()
+	for _, check := range checks {
+		server.AddCheck(check)
+	}
+	return server
+}
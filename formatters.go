@@ -0,0 +1,231 @@
+package updown
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// EventFormatter turns an event into the body of a request sent to a downstream URL
+type EventFormatter interface {
+	// ContentType is the media type of the formatted events
+	ContentType() string
+	Format(event Event) ([]byte, error)
+}
+
+// JSONFormatter formats events as the JSON sent by Updown
+type JSONFormatter struct{}
+
+// ContentType is the media type of the formatted events
+func (JSONFormatter) ContentType() string {
+	return "application/json"
+}
+
+// Format formats an event as JSON
+func (JSONFormatter) Format(event Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// SlackFormatter formats events as Slack messages with blocks, for incoming webhooks of Slack
+// and of compatible chats
+type SlackFormatter struct{}
+
+// ContentType is the media type of the formatted events
+func (SlackFormatter) ContentType() string {
+	return "application/json"
+}
+
+// Format formats an event as a Slack message
+func (SlackFormatter) Format(event Event) ([]byte, error) {
+	type text struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	type block struct {
+		Type     string `json:"type"`
+		Text     *text  `json:"text,omitempty"`
+		Elements []text `json:"elements,omitempty"`
+	}
+
+	name := slackEscape(checkName(event.Check))
+	if event.Check.URL != "" {
+		name = fmt.Sprintf("<%s|%s>", slackEscape(event.Check.URL), name)
+	}
+	title := fmt.Sprintf("%s *%s* %s", eventEmoji(event.Event), name, eventVerb(event.Event))
+	blocks := []block{{Type: "section", Text: &text{Type: "mrkdwn", Text: title}}}
+
+	var details []text
+	if event.Description != "" {
+		details = append(details, text{Type: "mrkdwn", Text: slackEscape(event.Description)})
+	}
+	if detail := eventDetail(event); detail != "" {
+		details = append(details, text{Type: "mrkdwn", Text: slackEscape(detail)})
+	}
+	if len(details) > 0 {
+		blocks = append(blocks, block{Type: "context", Elements: details})
+	}
+
+	return json.Marshal(struct {
+		// Fallback for notifications
+		Text   string  `json:"text"`
+		Blocks []block `json:"blocks"`
+	}{eventSummary(event), blocks})
+}
+
+// TeamsFormatter formats events as Microsoft Teams message cards, for incoming webhooks of Teams
+type TeamsFormatter struct{}
+
+// ContentType is the media type of the formatted events
+func (TeamsFormatter) ContentType() string {
+	return "application/json"
+}
+
+// Format formats an event as a Microsoft Teams message card
+func (TeamsFormatter) Format(event Event) ([]byte, error) {
+	type target struct {
+		OS  string `json:"os"`
+		URI string `json:"uri"`
+	}
+	type action struct {
+		Type    string   `json:"@type"`
+		Name    string   `json:"name"`
+		Targets []target `json:"targets"`
+	}
+
+	text := event.Description
+	if detail := eventDetail(event); detail != "" {
+		text = strings.TrimSpace(text + "\n\n" + detail)
+	}
+	var actions []action
+	if event.Check.URL != "" {
+		actions = []action{{Type: "OpenUri", Name: "Open " + checkName(event.Check), Targets: []target{{OS: "default", URI: event.Check.URL}}}}
+	}
+
+	return json.Marshal(struct {
+		Type            string   `json:"@type"`
+		Context         string   `json:"@context"`
+		ThemeColor      string   `json:"themeColor"`
+		Summary         string   `json:"summary"`
+		Title           string   `json:"title"`
+		Text            string   `json:"text,omitempty"`
+		PotentialAction []action `json:"potentialAction,omitempty"`
+	}{
+		Type:            "MessageCard",
+		Context:         "https://schema.org/extensions",
+		ThemeColor:      eventColor(event.Event),
+		Summary:         eventSummary(event),
+		Title:           eventSummary(event),
+		Text:            text,
+		PotentialAction: actions,
+	})
+}
+
+// TemplateFormatter formats events with a text/template. Templates are executed with the event
+// as data, and can use the json function to encode a value as JSON.
+type TemplateFormatter struct {
+	template    *template.Template
+	contentType string
+}
+
+// NewTemplateFormatter parses a template formatting events, producing bodies of the given media type
+func NewTemplateFormatter(text, contentType string) (*TemplateFormatter, error) {
+	tmpl, err := template.New("event").Funcs(template.FuncMap{
+		"json": func(value interface{}) (string, error) {
+			data, err := json.Marshal(value)
+			return string(data), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateFormatter{template: tmpl, contentType: contentType}, nil
+}
+
+// ContentType is the media type of the formatted events
+func (f *TemplateFormatter) ContentType() string {
+	return f.contentType
+}
+
+// Format executes the template for an event
+func (f *TemplateFormatter) Format(event Event) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := f.template.Execute(&buf, event); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// checkName gives the alias of a check, or its URL when it has no alias
+func checkName(check Check) string {
+	if check.Alias != "" {
+		return check.Alias
+	}
+	return check.URL
+}
+
+// eventSummary describes an event in a single line of plain text
+func eventSummary(event Event) string {
+	return checkName(event.Check) + " " + eventVerb(event.Event)
+}
+
+// eventDetail gives details about an event specific to its type
+func eventDetail(event Event) string {
+	switch {
+	case event.Event == EventCheckDown && event.Downtime.Error != "":
+		return "Error: " + event.Downtime.Error
+	case event.Event == EventCheckUp && event.Downtime.DurationSeconds > 0:
+		return "Down for " + event.Downtime.Duration().String()
+	case event.SSL != nil && event.SSL.Error != "":
+		return "Error: " + event.SSL.Error
+	case event.SSL != nil && event.SSL.DaysBeforeExpiration > 0:
+		return fmt.Sprintf("Certificate expires in %d days", event.SSL.DaysBeforeExpiration)
+	case event.ApdexDropped != "":
+		return "Apdex dropped by " + event.ApdexDropped
+	}
+	return ""
+}
+
+func eventVerb(event EventType) string {
+	switch event {
+	case EventCheckDown:
+		return "is down"
+	case EventCheckUp:
+		return "is up"
+	case EventCheckSSLInvalid:
+		return "has an invalid SSL certificate"
+	case EventCheckSSLValid:
+		return "has a valid SSL certificate again"
+	case EventCheckSSLExpiration:
+		return "has an SSL certificate about to expire"
+	case EventCheckPerformanceDrop:
+		return "has a performance drop"
+	}
+	return string(event)
+}
+
+func eventEmoji(event EventType) string {
+	switch event {
+	case EventCheckDown, EventCheckSSLInvalid:
+		return ":red_circle:"
+	case EventCheckUp, EventCheckSSLValid:
+		return ":large_green_circle:"
+	}
+	return ":warning:"
+}
+
+func eventColor(event EventType) string {
+	switch event {
+	case EventCheckDown, EventCheckSSLInvalid:
+		return "D50200"
+	case EventCheckUp, EventCheckSSLValid:
+		return "2EB886"
+	}
+	return "FFA500"
+}
+
+// slackEscape escapes the characters with a special meaning in Slack messages
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
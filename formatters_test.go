@@ -0,0 +1,84 @@
+package updown
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var downEvent = Event{
+	Event:       EventCheckDown,
+	Description: "Google is DOWN",
+	Check:       Check{Token: "ngg8", URL: "https://google.fr/?a=1&b=2", Alias: "Google <prod>"},
+	Downtime:    Downtime{Error: "Connection refused"},
+}
+
+func TestJSONFormatter(t *testing.T) {
+	body, err := JSONFormatter{}.Format(downEvent)
+	assert.Nil(t, err)
+
+	events, err := ParseEvents(bytes.NewReader(body))
+	assert.Nil(t, err)
+	assert.Equal(t, downEvent.ID(), events[0].ID())
+	assert.Equal(t, "application/json", JSONFormatter{}.ContentType())
+}
+
+func TestSlackFormatter(t *testing.T) {
+	body, err := SlackFormatter{}.Format(downEvent)
+	assert.Nil(t, err)
+
+	var message struct {
+		Text   string
+		Blocks []struct {
+			Type string
+			Text struct {
+				Type string
+				Text string
+			}
+			Elements []struct{ Text string }
+		}
+	}
+	assert.Nil(t, json.Unmarshal(body, &message))
+	assert.Equal(t, "Google <prod> is down", message.Text)
+	assert.Len(t, message.Blocks, 2)
+	assert.Equal(t, "mrkdwn", message.Blocks[0].Text.Type)
+	assert.Equal(t, ":red_circle: *<https://google.fr/?a=1&amp;b=2|Google &lt;prod&gt;>* is down", message.Blocks[0].Text.Text)
+	assert.Equal(t, "context", message.Blocks[1].Type)
+	assert.Equal(t, "Error: Connection refused", message.Blocks[1].Elements[1].Text)
+}
+
+func TestTeamsFormatter(t *testing.T) {
+	body, err := TeamsFormatter{}.Format(Event{
+		Event: EventCheckSSLExpiration,
+		Check: Check{URL: "https://google.fr"},
+		SSL:   &EventSSL{DaysBeforeExpiration: 7},
+	})
+	assert.Nil(t, err)
+
+	var card map[string]interface{}
+	assert.Nil(t, json.Unmarshal(body, &card))
+	assert.Equal(t, "MessageCard", card["@type"])
+	assert.Equal(t, "FFA500", card["themeColor"])
+	assert.Equal(t, "https://google.fr has an SSL certificate about to expire", card["title"])
+	assert.Equal(t, "Certificate expires in 7 days", card["text"])
+	assert.Len(t, card["potentialAction"], 1)
+}
+
+func TestTemplateFormatter(t *testing.T) {
+	f, err := NewTemplateFormatter(`{"alert": {{json .Description}}, "token": "{{.Check.Token}}"}`, "application/json")
+	assert.Nil(t, err)
+	assert.Equal(t, "application/json", f.ContentType())
+
+	body, err := f.Format(downEvent)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"alert": "Google is DOWN", "token": "ngg8"}`, string(body))
+
+	_, err = NewTemplateFormatter("{{.Check", "text/plain")
+	assert.NotNil(t, err)
+
+	f, _ = NewTemplateFormatter("{{.Unknown}}", "text/plain")
+	_, err = f.Format(downEvent)
+	assert.NotNil(t, err)
+}
@@ -1,8 +1,16 @@
 package updown
 
 import (
+	"context"
 	"net/http"
 	"net/url"
+	"time"
+)
+
+// Groups available when listing metrics
+const (
+	MetricGroupHost = "host"
+	MetricGroupTime = "time"
 )
 
 // ResponseTime represents the response times in milliseconds
@@ -53,6 +61,28 @@ type MetricItem struct {
 // Metrics represents multiple metrics
 type Metrics map[string]MetricItem
 
+// MetricQuery describes which metrics to list for a check
+type MetricQuery struct {
+	// How metrics are grouped, MetricGroupHost or MetricGroupTime. Empty for no grouping
+	Group string
+	// Start of the period, the API defaults to one month ago when zero
+	From time.Time
+	// End of the period, the API defaults to now when zero
+	To time.Time
+}
+
+// Validate checks that the group is known and that the period is valid
+func (q MetricQuery) Validate() error {
+	errs := &ValidationError{}
+	if q.Group != "" && q.Group != MetricGroupHost && q.Group != MetricGroupTime {
+		errs.add("group", "must be host or time")
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		errs.add("from", "must be before to")
+	}
+	return errs.errorOrNil()
+}
+
 // MetricService interacts with the metrics section of the API
 type MetricService struct {
 	client *Client
@@ -61,6 +91,11 @@ type MetricService struct {
 // List lists metrics available for a check identified by a taken, grouped by the given group
 // (host|time) over a period
 func (s *MetricService) List(token, group, from, to string) (Metrics, *http.Response, error) {
+	return s.ListContext(context.Background(), token, group, from, to)
+}
+
+// ListContext is like List but takes a context for the request
+func (s *MetricService) ListContext(ctx context.Context, token, group, from, to string) (Metrics, *http.Response, error) {
 	u, _ := url.Parse(pathForToken(token) + "/metrics")
 	q := u.Query()
 	q.Add("group", group)
@@ -74,7 +109,7 @@ func (s *MetricService) List(token, group, from, to string) (Metrics, *http.Resp
 	}
 	u.RawQuery = q.Encode()
 
-	req, err := s.client.NewRequest("GET", u.String(), nil)
+	req, err := s.client.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -87,3 +122,25 @@ func (s *MetricService) List(token, group, from, to string) (Metrics, *http.Resp
 
 	return res, resp, err
 }
+
+// ListQuery lists metrics available for a check identified by a token. The query is validated
+// before sending the request.
+func (s *MetricService) ListQuery(token string, query MetricQuery) (Metrics, *http.Response, error) {
+	return s.ListQueryContext(context.Background(), token, query)
+}
+
+// ListQueryContext is like ListQuery but takes a context for the request
+func (s *MetricService) ListQueryContext(ctx context.Context, token string, query MetricQuery) (Metrics, *http.Response, error) {
+	if err := query.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return s.ListContext(ctx, token, query.Group, formatQueryTime(query.From), formatQueryTime(query.To))
+}
+
+// formatQueryTime formats a time for a query string, a zero time is omitted
+func formatQueryTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
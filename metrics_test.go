@@ -0,0 +1,52 @@
+package updown
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricQueryValidate(t *testing.T) {
+	now := time.Now()
+	assert.Nil(t, MetricQuery{}.Validate())
+	assert.Nil(t, MetricQuery{Group: MetricGroupTime, From: now.Add(-time.Hour), To: now}.Validate())
+
+	err := MetricQuery{Group: "foo", From: now, To: now.Add(-time.Hour)}.Validate()
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.Equal(t, []FieldError{
+		{Field: "group", Message: "must be host or time"},
+		{Field: "from", Message: "must be before to"},
+	}, err.(*ValidationError).Fields)
+}
+
+func TestListQuery(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"gra":{"apdex":0.9}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("key", nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/api/")
+
+	from := time.Date(2016, 4, 1, 0, 0, 0, 0, time.FixedZone("CEST", 7200))
+	metrics, _, err := client.Metric.ListQuery("foo", MetricQuery{Group: MetricGroupHost, From: from})
+	assert.Nil(t, err)
+	assert.Equal(t, 0.9, metrics["gra"].Apdex)
+	assert.Equal(t, "host", query.Get("group"))
+	assert.Equal(t, "2016-04-01T00:00:00+02:00", query.Get("from"))
+	assert.Equal(t, "", query.Get("to"))
+
+	// Invalid queries are not sent
+	query = nil
+	_, resp, err := client.Metric.ListQuery("foo", MetricQuery{Group: "foo"})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.Nil(t, query)
+}
@@ -1,6 +1,7 @@
 package updown
 
 import (
+	"context"
 	"net/http"
 )
 
@@ -26,7 +27,12 @@ type Nodes map[string]NodeDetails
 
 // List gets the nodes performing checks
 func (s *NodeService) List() (Nodes, *http.Response, error) {
-	req, err := s.client.NewRequest("GET", "nodes", nil)
+	return s.ListContext(context.Background())
+}
+
+// ListContext is like List but takes a context for the request
+func (s *NodeService) ListContext(ctx context.Context) (Nodes, *http.Response, error) {
+	req, err := s.client.NewRequestWithContext(ctx, "GET", "nodes", nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -42,17 +48,27 @@ func (s *NodeService) List() (Nodes, *http.Response, error) {
 
 // ListIPv4 gets the list of IPv4 performing checks
 func (s *NodeService) ListIPv4() (IPs, *http.Response, error) {
-	return s.genericIPList("4")
+	return s.genericIPList(context.Background(), "4")
+}
+
+// ListIPv4Context is like ListIPv4 but takes a context for the request
+func (s *NodeService) ListIPv4Context(ctx context.Context) (IPs, *http.Response, error) {
+	return s.genericIPList(ctx, "4")
 }
 
 // ListIPv6 gets the list of IPv6 performing checks
 func (s *NodeService) ListIPv6() (IPs, *http.Response, error) {
-	return s.genericIPList("6")
+	return s.genericIPList(context.Background(), "6")
+}
+
+// ListIPv6Context is like ListIPv6 but takes a context for the request
+func (s *NodeService) ListIPv6Context(ctx context.Context) (IPs, *http.Response, error) {
+	return s.genericIPList(ctx, "6")
 }
 
 // genericIPList get the list of IPv4 or IPv6 IPs performing checks
-func (s *NodeService) genericIPList(version string) (IPs, *http.Response, error) {
-	req, err := s.client.NewRequest("GET", "nodes/ipv"+version, nil)
+func (s *NodeService) genericIPList(ctx context.Context, version string) (IPs, *http.Response, error) {
+	req, err := s.client.NewRequestWithContext(ctx, "GET", "nodes/ipv"+version, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,60 @@
+package updown
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ClientOption configures a Client created with NewClient
+type ClientOption func(*Client)
+
+// WithRateLimit limits the client to perSecond requests per second on average, with bursts
+// of up to burst requests. The limit is shared by all the goroutines using the client.
+func WithRateLimit(perSecond float64, burst int) ClientOption {
+	return func(c *Client) {
+		c.limiter = NewRateLimiter(perSecond, burst)
+	}
+}
+
+// WithCache sets the cache used by the check service to find tokens for aliases
+func WithCache(cache Cache) ClientOption {
+	return func(c *Client) {
+		c.Check.cache = cache
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to communicate with the API
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.client = httpClient
+		}
+	}
+}
+
+// WithBaseURL sets the base URL for API requests, for instance to use a proxy or a fake API.
+// A trailing slash is added to the path if it is missing.
+func WithBaseURL(baseURL *url.URL) ClientOption {
+	return func(c *Client) {
+		u := *baseURL
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		c.BaseURL = &u
+	}
+}
+
+// WithUserAgent sets the user agent sent with every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.UserAgent = userAgent
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry failed requests
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.RetryPolicy = policy
+	}
+}
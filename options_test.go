@@ -0,0 +1,43 @@
+package updown
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientOptions(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		assert.Equal(t, "/api/checks", r.URL.Path)
+		w.Write([]byte(`[{"token":"abcd","alias":"Google"}]`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/api")
+	cache := NewMemoryCacheWithTTL(time.Hour)
+	httpClient := &http.Client{}
+	client := NewClient("key", nil,
+		WithHTTPClient(httpClient),
+		WithBaseURL(baseURL),
+		WithUserAgent("my-agent"),
+		WithCache(cache),
+		WithRetryPolicy(RetryPolicy{}),
+	)
+
+	assert.Equal(t, httpClient, client.client)
+	assert.Equal(t, server.URL+"/api/", client.BaseURL.String())
+	assert.Equal(t, "/api", baseURL.Path)
+	assert.Equal(t, RetryPolicy{}, client.RetryPolicy)
+
+	token, err := client.Check.TokenForAlias("Google")
+	assert.Nil(t, err)
+	assert.Equal(t, "abcd", token)
+	assert.Equal(t, "my-agent", userAgent)
+	assert.True(t, cache.Has("Google"))
+}
@@ -0,0 +1,332 @@
+package updown
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ReconcileKey tells how desired checks are matched with existing ones
+type ReconcileKey int
+
+const (
+	// KeyByAlias matches checks by alias, and checks without an alias by URL
+	KeyByAlias ReconcileKey = iota
+	// KeyByURL matches checks by URL. Checks sharing a URL are matched in order.
+	KeyByURL
+)
+
+// PlanAction is what a planned change does to a check
+type PlanAction string
+
+// Actions of a plan
+const (
+	ActionCreate PlanAction = "create"
+	ActionUpdate PlanAction = "update"
+	ActionDelete PlanAction = "delete"
+	ActionNoop   PlanAction = "no-op"
+)
+
+// FieldDiff describes how an update changes a field of a check
+type FieldDiff struct {
+	// Name of the field, as sent to the API
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s: %v -> %v", d.Field, d.Old, d.New)
+}
+
+// PlannedChange is a change to apply to a check
+type PlannedChange struct {
+	Action PlanAction
+	// Alias or URL of the check, depending on the key of the reconciliation
+	Key string
+	// Token of the existing check, empty for creations
+	Token string
+	// Desired state of the check, empty for deletions
+	Desired CheckItem
+	// Changed fields, for updates
+	Diffs []FieldDiff
+}
+
+// patch gives the patch sending the desired value of every changed field
+func (c PlannedChange) patch() CheckPatch {
+	var patch CheckPatch
+	item := c.Desired
+	for _, diff := range c.Diffs {
+		switch diff.Field {
+		case "url":
+			patch.URL = String(item.URL)
+		case "alias":
+			patch.Alias = String(item.Alias)
+		case "period":
+			patch.Period = Int(item.Period)
+		case "apdex_t":
+			patch.Apdex = Float64(item.Apdex)
+		case "enabled":
+			patch.Enabled = Bool(item.Enabled)
+		case "published":
+			patch.Published = Bool(item.Published)
+		case "string_match":
+			patch.StringMatch = String(item.StringMatch)
+		case "mute_until":
+			patch.MuteUntil = String(item.MuteUntil)
+		case "disabled_locations":
+			patch.DisabledLocations = Strings(item.DisabledLocations)
+		case "custom_headers":
+			patch.CustomHeaders = StringMap(item.CustomHeaders)
+		}
+	}
+	return patch
+}
+
+// Plan lists the changes needed to reach a desired set of checks
+type Plan struct {
+	Changes []PlannedChange
+}
+
+// HasChanges tells if applying the plan would change anything
+func (p Plan) HasChanges() bool {
+	return p.Count(ActionCreate)+p.Count(ActionUpdate)+p.Count(ActionDelete) > 0
+}
+
+// Count gives the number of changes of the plan performing an action
+func (p Plan) Count(action PlanAction) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// String describes the changes of the plan, one per line. No-ops are omitted.
+func (p Plan) String() string {
+	var b strings.Builder
+	symbols := map[PlanAction]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}
+	for _, change := range p.Changes {
+		if change.Action == ActionNoop {
+			continue
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", symbols[change.Action], change.Action, change.Key)
+		for _, diff := range change.Diffs {
+			fmt.Fprintf(&b, "    %s\n", diff)
+		}
+	}
+	return b.String()
+}
+
+// ReconcileOptions configures how desired checks are reconciled with existing ones
+type ReconcileOptions struct {
+	// How desired checks are matched with existing ones
+	Key ReconcileKey
+	// Delete existing checks which are not desired. Unmanaged checks are left untouched otherwise
+	Prune bool
+	// Only compute the plan, without applying it
+	DryRun bool
+}
+
+// Reconcile plans the changes needed to reach the desired checks, and applies them unless
+// running in dry-run mode. The plan is returned in both cases.
+func (s *CheckService) Reconcile(desired []CheckItem, opts ReconcileOptions) (Plan, error) {
+	return s.ReconcileContext(context.Background(), desired, opts)
+}
+
+// ReconcileContext is like Reconcile but takes a context for the requests
+func (s *CheckService) ReconcileContext(ctx context.Context, desired []CheckItem, opts ReconcileOptions) (Plan, error) {
+	plan, err := s.PlanContext(ctx, desired, opts)
+	if err != nil || opts.DryRun {
+		return plan, err
+	}
+	return plan, s.ApplyContext(ctx, plan)
+}
+
+// Plan computes the changes needed to reach the desired checks, without applying them
+func (s *CheckService) Plan(desired []CheckItem, opts ReconcileOptions) (Plan, error) {
+	return s.PlanContext(context.Background(), desired, opts)
+}
+
+// PlanContext is like Plan but takes a context for the request
+func (s *CheckService) PlanContext(ctx context.Context, desired []CheckItem, opts ReconcileOptions) (Plan, error) {
+	errs := &ValidationError{}
+	aliases := make(map[string]bool, len(desired))
+	for _, item := range desired {
+		if opts.Key == KeyByAlias && item.Alias != "" {
+			if aliases[item.Alias] {
+				errs.add("alias", "is not unique: "+item.Alias)
+			}
+			aliases[item.Alias] = true
+		}
+
+		if err, ok := item.ValidateNew().(*ValidationError); ok {
+			errs.Fields = append(errs.Fields, err.Fields...)
+		}
+	}
+	if err := errs.errorOrNil(); err != nil {
+		return Plan{}, err
+	}
+
+	checks, _, err := s.ListContext(ctx)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	existing := make(map[string][]Check, len(checks))
+	for _, check := range checks {
+		key := reconcileKey(opts.Key, check.Alias, check.URL)
+		existing[key] = append(existing[key], check)
+	}
+	for alias := range aliases {
+		if len(existing[alias]) > 1 {
+			return Plan{}, fmt.Errorf("%s: %w", alias, ErrAmbiguousMatch)
+		}
+	}
+
+	var plan Plan
+	matched := make(map[string]bool, len(checks))
+	for _, item := range desired {
+		key := reconcileKey(opts.Key, item.Alias, item.URL)
+		candidates := existing[key]
+		if len(candidates) == 0 {
+			plan.Changes = append(plan.Changes, PlannedChange{Action: ActionCreate, Key: key, Desired: item})
+			continue
+		}
+
+		// Checks sharing a URL are matched in order
+		check := candidates[0]
+		existing[key] = candidates[1:]
+		matched[check.Token] = true
+
+		change := PlannedChange{Action: ActionNoop, Key: key, Token: check.Token, Desired: item}
+		change.Diffs = diffCheck(check, item)
+		if len(change.Diffs) > 0 {
+			change.Action = ActionUpdate
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	if opts.Prune {
+		for _, check := range checks {
+			if !matched[check.Token] {
+				key := reconcileKey(opts.Key, check.Alias, check.URL)
+				plan.Changes = append(plan.Changes, PlannedChange{Action: ActionDelete, Key: key, Token: check.Token})
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// Apply applies the changes of a plan, in order. It stops at the first error.
+func (s *CheckService) Apply(plan Plan) error {
+	return s.ApplyContext(context.Background(), plan)
+}
+
+// ApplyContext is like Apply but takes a context for the requests
+func (s *CheckService) ApplyContext(ctx context.Context, plan Plan) error {
+	for _, change := range plan.Changes {
+		var err error
+		switch change.Action {
+		case ActionCreate:
+			_, _, err = s.AddContext(ctx, change.Desired)
+		case ActionUpdate:
+			_, _, err = s.PatchContext(ctx, change.Token, change.patch())
+		case ActionDelete:
+			_, _, err = s.RemoveContext(ctx, change.Token)
+		}
+		if err != nil {
+			return fmt.Errorf("Cannot %s %s: %w", change.Action, change.Key, err)
+		}
+	}
+	return nil
+}
+
+// diffCheck compares an existing check with its desired state. A zero period or APDEX threshold
+// uses the value of the API, they are not compared. Disabled locations are compared as a set,
+// and mute times once parsed.
+func diffCheck(check Check, item CheckItem) []FieldDiff {
+	var diffs []FieldDiff
+	diff := func(field string, old, new interface{}, equal bool) {
+		if !equal {
+			diffs = append(diffs, FieldDiff{Field: field, Old: old, New: new})
+		}
+	}
+
+	diff("url", check.URL, item.URL, check.URL == item.URL)
+	diff("alias", check.Alias, item.Alias, check.Alias == item.Alias)
+	if item.Period != 0 {
+		diff("period", check.Period, item.Period, check.Period == item.Period)
+	}
+	if item.Apdex != 0 {
+		diff("apdex_t", check.Apdex, item.Apdex, check.Apdex == item.Apdex)
+	}
+	diff("enabled", check.Enabled, item.Enabled, check.Enabled == item.Enabled)
+	diff("published", check.Published, item.Published, check.Published == item.Published)
+	diff("string_match", check.StringMatch, item.StringMatch, check.StringMatch == item.StringMatch)
+	diff("mute_until", check.MuteUntil, item.MuteUntil, sameMuteUntil(check.MuteUntil, item.MuteUntil))
+	diff("disabled_locations", emptyIfNil(check.DisabledLocations), emptyIfNil(item.DisabledLocations),
+		sameSet(check.DisabledLocations, item.DisabledLocations))
+	diff("custom_headers", emptyMapIfNil(check.CustomHeaders), emptyMapIfNil(item.CustomHeaders),
+		reflect.DeepEqual(emptyMapIfNil(check.CustomHeaders), emptyMapIfNil(item.CustomHeaders)))
+
+	return diffs
+}
+
+// sameMuteUntil tells if two mute_until values are equal. Times are compared once parsed, since
+// the API does not give them back in the format they were sent. Other values, such as "recovery"
+// or "forever", are compared as they are.
+func sameMuteUntil(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ta, errA := parseTime(a)
+	tb, errB := parseTime(b)
+	return errA == nil && errB == nil && !ta.IsZero() && ta.Equal(tb)
+}
+
+// sameSet tells if two lists hold the same values, whatever their order
+func sameSet(a, b []string) bool {
+	set := make(map[string]int, len(a))
+	for _, value := range a {
+		set[value]++
+	}
+	for _, value := range b {
+		if set[value] == 0 {
+			return false
+		}
+		set[value]--
+	}
+	for _, count := range set {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// reconcileKey gives the key matching a check. Checks without an alias are matched by URL.
+func reconcileKey(key ReconcileKey, alias, rawURL string) string {
+	if key == KeyByURL || alias == "" {
+		return rawURL
+	}
+	return alias
+}
+
+func emptyIfNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func emptyMapIfNil(values map[string]string) map[string]string {
+	if values == nil {
+		return map[string]string{}
+	}
+	return values
+}
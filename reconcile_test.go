@@ -0,0 +1,170 @@
+package updown_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/antoineaugusti/updown"
+	"github.com/stretchr/testify/assert"
+)
+
+var reconcileChecks = []updown.Check{
+	{Token: "a", URL: "https://google.fr", Alias: "Google", Enabled: true, Period: 60},
+	{Token: "b", URL: "https://bing.com", Alias: "Bing", Enabled: true, StringMatch: "Bing"},
+	{Token: "c", URL: "https://example.com", Alias: "Unmanaged", Enabled: true},
+}
+
+func desiredChecks() []updown.CheckItem {
+	return []updown.CheckItem{
+		{URL: "https://google.fr", Alias: "Google", Enabled: true},
+		{URL: "https://bing.com", Alias: "Bing", Enabled: true, Period: 300},
+		{URL: "https://duckduckgo.com", Alias: "DuckDuckGo", Enabled: true},
+	}
+}
+
+func TestPlan(t *testing.T) {
+	server := newChecksServer(reconcileChecks...)
+	defer server.Close()
+	client := server.Client()
+
+	plan, err := client.Check.Plan(desiredChecks(), updown.ReconcileOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, []updown.PlanAction{updown.ActionNoop, updown.ActionUpdate, updown.ActionCreate}, actions(plan))
+	assert.Equal(t, []updown.FieldDiff{
+		{Field: "period", Old: 60, New: 300},
+		{Field: "string_match", Old: "Bing", New: ""},
+	}, plan.Changes[1].Diffs)
+	assert.Equal(t, "~ update Bing\n    period: 60 -> 300\n    string_match: Bing -> \n+ create DuckDuckGo\n", plan.String())
+
+	// Unmanaged checks are deleted only when pruning
+	plan, err = client.Check.Plan(desiredChecks(), updown.ReconcileOptions{Prune: true, Key: updown.KeyByURL})
+	assert.Nil(t, err)
+	assert.Equal(t, updown.ActionDelete, plan.Changes[3].Action)
+	assert.Equal(t, "c", plan.Changes[3].Token)
+	assert.Equal(t, "https://example.com", plan.Changes[3].Key)
+
+	// Invalid desired checks
+	_, err = client.Check.Plan([]updown.CheckItem{
+		{URL: "https://google.fr", Alias: "Google"},
+		{URL: "https://google.com", Alias: "Google"},
+	}, updown.ReconcileOptions{})
+	assert.True(t, errors.Is(err, updown.ErrValidation))
+}
+
+func TestReconcile(t *testing.T) {
+	server := newChecksServer(reconcileChecks...)
+	defer server.Close()
+	client := server.Client()
+	opts := updown.ReconcileOptions{Prune: true, DryRun: true}
+
+	// Nothing changes in dry-run mode
+	plan, err := client.Check.Reconcile(desiredChecks(), opts)
+	assert.Nil(t, err)
+	assert.True(t, plan.HasChanges())
+	assert.Len(t, server.Checks(), 3)
+
+	opts.DryRun = false
+	plan, err = client.Check.Reconcile(desiredChecks(), opts)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, plan.Count(updown.ActionCreate))
+	assert.Equal(t, 1, plan.Count(updown.ActionUpdate))
+	assert.Equal(t, 1, plan.Count(updown.ActionDelete))
+
+	checks := server.Checks()
+	assert.Len(t, checks, 3)
+	assert.Equal(t, 300, checks[1].Period)
+	assert.Equal(t, "", checks[1].StringMatch)
+	assert.Equal(t, "DuckDuckGo", checks[2].Alias)
+
+	// Applying again is a no-op
+	plan, err = client.Check.Reconcile(desiredChecks(), opts)
+	assert.Nil(t, err)
+	assert.False(t, plan.HasChanges())
+}
+
+func TestPlanComparesLocationsAndMuteTimes(t *testing.T) {
+	server := newChecksServer(updown.Check{
+		Token: "a", URL: "https://bing.com", Alias: "Bing", Enabled: true,
+		MuteUntil: "2030-01-02 04:04:05 +0100", DisabledLocations: []string{"lan", "mia"},
+	})
+	defer server.Close()
+	client := server.Client()
+
+	// Same locations in another order, and the same time in another format
+	plan, err := client.Check.Plan([]updown.CheckItem{
+		{URL: "https://bing.com", Alias: "Bing", Enabled: true, MuteUntil: "2030-01-02T03:04:05Z", DisabledLocations: []string{"mia", "lan"}},
+	}, updown.ReconcileOptions{})
+	assert.Nil(t, err)
+	assert.False(t, plan.HasChanges())
+
+	plan, err = client.Check.Plan([]updown.CheckItem{
+		{URL: "https://bing.com", Alias: "Bing", Enabled: true, MuteUntil: "2030-01-02T04:04:05Z", DisabledLocations: []string{"mia"}},
+	}, updown.ReconcileOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, []updown.FieldDiff{
+		{Field: "mute_until", Old: "2030-01-02 04:04:05 +0100", New: "2030-01-02T04:04:05Z"},
+		{Field: "disabled_locations", Old: []string{"lan", "mia"}, New: []string{"mia"}},
+	}, plan.Changes[0].Diffs)
+}
+
+func TestPlanWithoutAliases(t *testing.T) {
+	server := newChecksServer(
+		updown.Check{Token: "a", URL: "https://google.fr", Alias: "Google", Enabled: true},
+		updown.Check{Token: "b", URL: "https://bing.com", Enabled: true},
+		updown.Check{Token: "c", URL: "https://bing.com", Enabled: true, StringMatch: "Bing"},
+		updown.Check{Token: "d", URL: "https://bing.com", Enabled: true},
+	)
+	defer server.Close()
+	client := server.Client()
+
+	desired := []updown.CheckItem{
+		{URL: "https://google.fr", Alias: "Google", Enabled: true},
+		{URL: "https://bing.com", Enabled: true},
+		{URL: "https://bing.com", Enabled: true, StringMatch: "Bing"},
+	}
+	for _, key := range []updown.ReconcileKey{updown.KeyByAlias, updown.KeyByURL} {
+		// Checks without an alias are matched by URL, checks sharing a URL in order
+		plan, err := client.Check.Plan(desired, updown.ReconcileOptions{Key: key, Prune: true})
+		assert.Nil(t, err)
+		assert.Equal(t, []updown.PlanAction{updown.ActionNoop, updown.ActionNoop, updown.ActionNoop, updown.ActionDelete}, actions(plan))
+		assert.Equal(t, []string{"a", "b", "c", "d"}, []string{plan.Changes[0].Token, plan.Changes[1].Token, plan.Changes[2].Token, plan.Changes[3].Token})
+		assert.Equal(t, "https://bing.com", plan.Changes[1].Key)
+	}
+
+	// Aliases must still be unique
+	_, err := client.Check.Plan([]updown.CheckItem{
+		{URL: "https://bing.com", Alias: "Bing"},
+		{URL: "https://bing.com", Alias: "Bing"},
+	}, updown.ReconcileOptions{})
+	assert.True(t, errors.Is(err, updown.ErrValidation))
+}
+
+func TestApplyRebuiltPlan(t *testing.T) {
+	server := newChecksServer(reconcileChecks...)
+	defer server.Close()
+	client := server.Client()
+
+	plan, err := client.Check.Plan(desiredChecks(), updown.ReconcileOptions{})
+	assert.Nil(t, err)
+
+	// A plan saved and loaded, for instance to be reviewed before being applied
+	data, err := json.Marshal(plan)
+	assert.Nil(t, err)
+	var rebuilt updown.Plan
+	assert.Nil(t, json.Unmarshal(data, &rebuilt))
+
+	assert.Nil(t, client.Check.Apply(rebuilt))
+	checks := server.Checks()
+	assert.Equal(t, 300, checks[1].Period)
+	assert.Equal(t, "", checks[1].StringMatch)
+	assert.Equal(t, "DuckDuckGo", checks[3].Alias)
+}
+
+func actions(plan updown.Plan) []updown.PlanAction {
+	res := []updown.PlanAction{}
+	for _, change := range plan.Changes {
+		res = append(res, change.Action)
+	}
+	return res
+}
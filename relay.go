@@ -0,0 +1,130 @@
+package updown
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// RelayTarget is a downstream URL to which a relay sends events
+type RelayTarget struct {
+	URL string
+	// Formats the events, JSONFormatter when nil
+	Formatter EventFormatter
+	// Types of the events sent, all events when empty
+	Events []EventType
+}
+
+// accepts tells if events of the given type are sent to the target
+func (t RelayTarget) accepts(event EventType) bool {
+	if len(t.Events) == 0 {
+		return true
+	}
+	for _, e := range t.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Relay forwards the events posted by Updown to downstream URLs, formatted for each of them.
+// Failed requests are retried according to the retry policy. It is an http.Handler, and its
+// Send method can also be registered as an event handler of a WebhookHandler.
+type Relay struct {
+	Targets []RelayTarget
+	// HTTP client sending the events to the targets
+	HTTPClient *http.Client
+	// Policy retrying the requests to the targets
+	RetryPolicy RetryPolicy
+}
+
+// NewRelay creates a relay sending events to the given targets. POST requests are retried
+// with the default retry policy.
+func NewRelay(targets ...RelayTarget) *Relay {
+	policy := DefaultRetryPolicy()
+	policy.Methods = []string{"POST"}
+	return &Relay{Targets: targets, HTTPClient: http.DefaultClient, RetryPolicy: policy}
+}
+
+// Send sends an event to all the targets accepting it. The event is sent to every target
+// even when sending it to one of them fails.
+func (r *Relay) Send(event Event) error {
+	return r.SendContext(context.Background(), event)
+}
+
+// SendContext is like Send but takes a context for the requests
+func (r *Relay) SendContext(ctx context.Context, event Event) error {
+	var failed []error
+	for _, target := range r.Targets {
+		if !target.accepts(event.Event) {
+			continue
+		}
+		if err := r.send(ctx, target, event); err != nil {
+			failed = append(failed, fmt.Errorf("%s: %w", target.URL, err))
+		}
+	}
+
+	switch len(failed) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("Cannot relay %s: %w", event.Event, failed[0])
+	}
+	return fmt.Errorf("Cannot relay %s to %d targets, first error for %w", event.Event, len(failed), failed[0])
+}
+
+// ServeHTTP parses the events of a request and relays them. It answers with a 400 status code
+// when the payload is invalid or larger than MaxWebhookBodySize, and with a 502 status code
+// when an event cannot be relayed.
+func (r *Relay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	events, ok := parseRequest(w, req)
+	if !ok {
+		return
+	}
+
+	for _, event := range events {
+		if err := r.SendContext(req.Context(), event); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// send formats an event for a target and posts it
+func (r *Relay) send(ctx context.Context, target RelayTarget, event Event) error {
+	formatter := target.Formatter
+	if formatter == nil {
+		formatter = JSONFormatter{}
+	}
+	body, err := formatter.Format(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", target.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", formatter.ContentType())
+
+	client := r.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := sendWithRetry(client, r.RetryPolicy, nil, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
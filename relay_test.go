@@ -0,0 +1,108 @@
+package updown
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingServer records the bodies and content types it receives, failing the first requests
+type recordingServer struct {
+	*httptest.Server
+	mu           sync.Mutex
+	failures     int
+	bodies       []string
+	contentTypes []string
+}
+
+func newRecordingServer(failures int) *recordingServer {
+	s := &recordingServer{failures: failures}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.failures > 0 {
+			s.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		s.bodies = append(s.bodies, string(body))
+		s.contentTypes = append(s.contentTypes, r.Header.Get("Content-Type"))
+	}))
+	return s
+}
+
+func newTestRelay(targets ...RelayTarget) *Relay {
+	relay := NewRelay(targets...)
+	relay.RetryPolicy.MinBackoff = time.Millisecond
+	relay.RetryPolicy.MaxBackoff = 5 * time.Millisecond
+	return relay
+}
+
+func TestRelay(t *testing.T) {
+	slack := newRecordingServer(1)
+	defer slack.Close()
+	generic := newRecordingServer(0)
+	defer generic.Close()
+	text, _ := NewTemplateFormatter("{{.Event}} {{.Check.Token}}", "text/plain")
+
+	relay := newTestRelay(
+		RelayTarget{URL: slack.URL, Formatter: SlackFormatter{}, Events: []EventType{EventCheckDown, EventCheckUp}},
+		RelayTarget{URL: generic.URL, Formatter: text},
+	)
+
+	recorder := postWebhook(relay, "POST", webhookPayload)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The first attempt failed and was retried, other events are filtered out
+	assert.Len(t, slack.bodies, 1)
+	assert.Contains(t, slack.bodies[0], `"blocks"`)
+	assert.Equal(t, []string{"application/json"}, slack.contentTypes)
+	assert.Equal(t, []string{"check.down ngg8", "check.ssl_expiration ngg8", "check.unknown ngg8"}, generic.bodies)
+	assert.Equal(t, "text/plain", generic.contentTypes[0])
+
+	recorder = postWebhook(relay, "GET", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	recorder = postWebhook(relay, "POST", "not json")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestRelayFailure(t *testing.T) {
+	down := newRecordingServer(10)
+	defer down.Close()
+	up := newRecordingServer(0)
+	defer up.Close()
+
+	relay := newTestRelay(RelayTarget{URL: down.URL}, RelayTarget{URL: up.URL})
+	err := relay.Send(downEvent)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), down.URL)
+	assert.Contains(t, err.Error(), "503")
+	// Other targets still receive the event
+	assert.Len(t, up.bodies, 1)
+	assert.Equal(t, 7, down.failures)
+
+	recorder := postWebhook(relay, "POST", webhookPayload)
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+
+	recorder = postWebhook(relay, "POST", "["+strings.Repeat(" ", MaxWebhookBodySize)+"]")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestRelayAsEventHandler(t *testing.T) {
+	target := newRecordingServer(0)
+	defer target.Close()
+
+	handler := NewWebhookHandler()
+	handler.On(EventCheckDown, newTestRelay(RelayTarget{URL: target.URL}).Send)
+	recorder := postWebhook(handler, "POST", webhookPayload)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, target.bodies, 1)
+}
@@ -0,0 +1,174 @@
+package updown
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes when and how often a failed request is sent again by the client
+type RetryPolicy struct {
+	// Maximum number of attempts, including the first one. A value of 0 or 1 disables retries
+	MaxAttempts int
+
+	// Delay before the first retry. It is doubled after every attempt
+	MinBackoff time.Duration
+
+	// Upper bound of the delay between two attempts. A request is not retried when the server
+	// asks to wait for longer with a Retry-After header
+	MaxBackoff time.Duration
+
+	// HTTP status codes worth retrying
+	StatusCodes []int
+
+	// HTTP methods that can safely be retried
+	Methods []string
+}
+
+// DefaultRetryPolicy returns the policy used by clients created with NewClient.
+// Only idempotent read requests are retried, on transport errors, 429 and 5xx gateway errors.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+		StatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		Methods: []string{"GET", "HEAD"},
+	}
+}
+
+// shouldRetry tells if a request which led to the given response or error can be retried
+func (p RetryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil || !containsString(p.Methods, req.Method) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	for _, code := range p.StatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff computes how long to wait after the given attempt, which starts at 1.
+// A Retry-After header sent by the server takes precedence. It returns false when the
+// server asks to wait for longer than MaxBackoff: the request should not be retried.
+func (p RetryPolicy) backoff(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait, p.MaxBackoff <= 0 || wait <= p.MaxBackoff
+		}
+	}
+
+	wait := p.MinBackoff
+	for i := 1; i < attempt && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if wait <= 0 {
+		return 0, true
+	}
+
+	// Equal jitter: wait at least half of the computed delay
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1)), true
+}
+
+// parseRetryAfter parses a Retry-After header, expressed either in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// send sends a request, retrying it according to the retry policy of the client.
+// Every attempt waits on the rate limiter of the client, if any.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	return sendWithRetry(c.client, c.RetryPolicy, c.limiter, req)
+}
+
+// sendWithRetry sends a request with an HTTP client, retrying it according to a retry policy.
+// Every attempt waits on the rate limiter, unless it is nil.
+func sendWithRetry(client *http.Client, policy RetryPolicy, limiter *RateLimiter, req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		if limiter != nil {
+			if err := limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		response, err := client.Do(req)
+		if attempt >= policy.MaxAttempts || !policy.shouldRetry(req, response, err) {
+			return response, err
+		}
+
+		wait, ok := policy.backoff(attempt, response)
+		if !ok {
+			return response, err
+		}
+		if response != nil {
+			// Drain the body so that the connection can be reused
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+		}
+
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for the given duration, or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package updown
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFlakyServer(failures int32, status int, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(calls, 1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`[{"token":"abcd"}]`))
+	}))
+}
+
+func newRetryingClient(server *httptest.Server) *Client {
+	client := NewClient("key", nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/api/")
+	client.RetryPolicy.MinBackoff = time.Millisecond
+	client.RetryPolicy.MaxBackoff = 5 * time.Millisecond
+	return client
+}
+
+func TestRetryIdempotentRequest(t *testing.T) {
+	var calls int32
+	server := newFlakyServer(2, http.StatusServiceUnavailable, &calls)
+	defer server.Close()
+
+	checks, resp, err := newRetryingClient(server).Check.List()
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "abcd", checks[0].Token)
+	assert.Equal(t, int32(3), calls)
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	var calls int32
+	server := newFlakyServer(10, http.StatusTooManyRequests, &calls)
+	defer server.Close()
+
+	_, resp, err := newRetryingClient(server).Check.List()
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(3), calls)
+}
+
+func TestRetrySkipsNonIdempotentRequest(t *testing.T) {
+	var calls int32
+	server := newFlakyServer(1, http.StatusBadGateway, &calls)
+	defer server.Close()
+
+	_, resp, err := newRetryingClient(server).Check.Add(CheckItem{URL: "https://google.fr"})
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for i := 0; i < 20; i++ {
+		wait, _ := p.backoff(1, nil)
+		assert.True(t, wait >= 50*time.Millisecond && wait <= 100*time.Millisecond)
+		wait, _ = p.backoff(5, nil)
+		assert.True(t, wait >= 150*time.Millisecond && wait <= 300*time.Millisecond)
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"7"}}}
+	_, ok := p.backoff(1, resp)
+	assert.False(t, ok)
+
+	p.MaxBackoff = 10 * time.Second
+	wait, ok := p.backoff(1, resp)
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, wait)
+}
+
+func TestRetryAfterLongerThanMaxBackoff(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	_, _, err := newRetryingClient(server).Check.List()
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.Equal(t, int32(1), calls)
+	assert.True(t, time.Since(start) < time.Second)
+}
@@ -0,0 +1,144 @@
+package updown
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenForAliasCoalescesMisses(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		// Let other lookups pile up
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`[{"token":"abcd","alias":"Google"}]`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/api/")
+	client := NewClient("key", nil, WithBaseURL(baseURL))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 1 {
+				_, err := client.Check.TokenForAlias(fmt.Sprintf("missing %d", i))
+				assert.Equal(t, ErrTokenNotFound, err)
+				return
+			}
+			token, err := client.Check.TokenForAlias("Google")
+			assert.Nil(t, err)
+			assert.Equal(t, "abcd", token)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestTokenForAliasNegativeCache(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/api/")
+	clock := &fakeClock{now: time.Now()}
+	cache := NewMemoryCache()
+	client := NewClient("key", nil, WithBaseURL(baseURL), WithCache(cache))
+	client.Check.missing.now = clock.Now
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Check.TokenForAlias("foo")
+		assert.Equal(t, ErrTokenNotFound, err)
+	}
+	assert.Equal(t, int32(1), calls)
+	// Missing aliases are not stored in the cache of the user
+	assert.False(t, cache.Has("foo"))
+
+	// The alias is looked up again once the negative entry expired
+	clock.Advance(DefaultNegativeCacheTTL)
+	client.Check.TokenForAlias("foo")
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestTokenForAliasFirstCallerCancels(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`[{"token":"abcd","alias":"Google"}]`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/api/")
+	client := NewClient("key", nil, WithBaseURL(baseURL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := client.Check.TokenForAliasContext(ctx, "Google")
+		first <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	second := make(chan string)
+	go func() {
+		token, _ := client.Check.TokenForAliasContext(context.Background(), "Google")
+		second <- token
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// The first caller gives up, the shared request goes on for the second one
+	cancel()
+	assert.Equal(t, context.Canceled, <-first)
+	close(release)
+	assert.Equal(t, "abcd", <-second)
+}
+
+func TestTokenForAliasHungRequest(t *testing.T) {
+	var calls int32
+	cancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-r.Context().Done()
+			close(cancelled)
+			return
+		}
+		w.Write([]byte(`[{"token":"abcd","alias":"Google"}]`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/api/")
+	client := NewClient("key", nil, WithBaseURL(baseURL), WithRetryPolicy(RetryPolicy{}))
+
+	// The first request hangs, it is cancelled once its caller gives up
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := client.Check.TokenForAliasContext(ctx, "Google")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the hung request to be cancelled")
+	}
+
+	// The next lookup does not join it
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	token, err := client.Check.TokenForAliasContext(ctx, "Google")
+	assert.Nil(t, err)
+	assert.Equal(t, "abcd", token)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
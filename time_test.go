@@ -0,0 +1,46 @@
+package updown
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeJSON(t *testing.T) {
+	var check Check
+	data := `{"last_check_at":"2016-04-15T14:41:47Z","next_check_at":"2016-04-15 14:42:47 +0200","down_since":null,"ssl":{"tested_at":""}}`
+	assert.Nil(t, json.Unmarshal([]byte(data), &check))
+
+	assert.Equal(t, time.Date(2016, 4, 15, 14, 41, 47, 0, time.UTC), check.LastCheckAt.UTC())
+	assert.Equal(t, time.Date(2016, 4, 15, 12, 42, 47, 0, time.UTC), check.NextCheckAt.UTC())
+	assert.True(t, check.DownSince.IsZero())
+	assert.True(t, check.SSL.TestedAt.IsZero())
+
+	encoded, _ := json.Marshal(Downtime{StartedAt: check.LastCheckAt})
+	assert.Equal(t, `{"started_at":"2016-04-15T14:41:47Z","ended_at":null}`, string(encoded))
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"last_check_at":"yesterday"}`), &check))
+}
+
+func TestMuteUntilTime(t *testing.T) {
+	until, muted := Check{}.MuteUntilTime()
+	assert.False(t, muted)
+
+	until, muted = Check{MuteUntil: "forever"}.MuteUntilTime()
+	assert.True(t, muted)
+	assert.True(t, until.IsZero())
+
+	until, muted = Check{MuteUntil: "2016-04-15T14:41:47Z"}.MuteUntilTime()
+	assert.True(t, muted)
+	assert.Equal(t, 2016, until.Year())
+}
+
+func TestDowntimeDuration(t *testing.T) {
+	start := time.Date(2016, 4, 15, 14, 0, 0, 0, time.UTC)
+	assert.Equal(t, 90*time.Second, Downtime{DurationSeconds: 90}.Duration())
+	assert.Equal(t, time.Hour, Downtime{StartedAt: Time{start}, EndedAt: Time{start.Add(time.Hour)}}.Duration())
+	assert.True(t, Downtime{StartedAt: Time{time.Now().Add(-time.Minute)}}.Duration() >= time.Minute)
+	assert.Equal(t, time.Duration(0), Downtime{}.Duration())
+}
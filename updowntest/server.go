@@ -0,0 +1,380 @@
+// Package updowntest provides an in-memory fake of the Updown API, to test code built on
+// top of the updown package without hitting updown.io.
+package updowntest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/antoineaugusti/updown"
+)
+
+// DefaultAPIKey is the API key accepted by a server created with NewServer
+const DefaultAPIKey = "updowntest-api-key"
+
+// downtimesPerPage is the number of downtimes returned per page by the API
+const downtimesPerPage = 100
+
+// Server is a fake Updown API keeping its state in memory. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// APIKey expected in the X-API-KEY header of every request
+	APIKey string
+
+	mu        sync.Mutex
+	checks    map[string]updown.Check
+	order     []string
+	downtimes map[string][]updown.Downtime
+	metrics   map[string]updown.Metrics
+	nodes     updown.Nodes
+	webhooks  []updown.Webhook
+	lastID    int
+}
+
+// NewServer starts a new fake API with no checks nor webhooks, and the nodes of updown.io.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		APIKey:    DefaultAPIKey,
+		checks:    make(map[string]updown.Check),
+		downtimes: make(map[string][]updown.Downtime),
+		metrics:   make(map[string]updown.Metrics),
+		nodes:     defaultNodes(),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client using the API key of the server and pointed at it.
+// Options are applied after the ones pointing the client at the server.
+func (s *Server) Client(opts ...updown.ClientOption) *updown.Client {
+	baseURL, _ := url.Parse(s.URL + "/api/")
+	defaults := []updown.ClientOption{
+		updown.WithHTTPClient(s.Server.Client()),
+		updown.WithBaseURL(baseURL),
+	}
+	return updown.NewClient(s.APIKey, nil, append(defaults, opts...)...)
+}
+
+// AddCheck stores a check, generating a token if it has none, and returns it
+func (s *Server) AddCheck(check updown.Check) updown.Check {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.putCheck(check)
+}
+
+// Checks returns the checks currently stored, in creation order
+func (s *Server) Checks() []updown.Check {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.listChecks()
+}
+
+// AddDowntimes stores downtimes for a check. Downtimes are returned by the API in the given order.
+func (s *Server) AddDowntimes(token string, downtimes ...updown.Downtime) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.downtimes[token] = append(s.downtimes[token], downtimes...)
+}
+
+// SetMetrics sets the metrics returned for a check, whatever the group and period requested
+func (s *Server) SetMetrics(token string, metrics updown.Metrics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.metrics[token] = metrics
+}
+
+// Webhooks returns the webhooks currently stored, in creation order
+func (s *Server) Webhooks() []updown.Webhook {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]updown.Webhook{}, s.webhooks...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-API-KEY") != s.APIKey {
+		writeError(w, http.StatusUnauthorized, "Invalid API key")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/"), "/")
+	parts := strings.Split(path, "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case path == "checks" && r.Method == "GET":
+		writeJSON(w, http.StatusOK, s.listChecks())
+	case path == "checks" && r.Method == "POST":
+		s.createCheck(w, r)
+	case len(parts) == 2 && parts[0] == "checks":
+		s.serveCheck(w, r, parts[1])
+	case len(parts) == 3 && parts[0] == "checks" && parts[2] == "downtimes" && r.Method == "GET":
+		s.listDowntimes(w, r, parts[1])
+	case len(parts) == 3 && parts[0] == "checks" && parts[2] == "metrics" && r.Method == "GET":
+		s.listMetrics(w, r, parts[1])
+	case path == "nodes" && r.Method == "GET":
+		writeJSON(w, http.StatusOK, s.nodes)
+	case path == "nodes/ipv4" && r.Method == "GET":
+		writeJSON(w, http.StatusOK, s.nodeIPs(false))
+	case path == "nodes/ipv6" && r.Method == "GET":
+		writeJSON(w, http.StatusOK, s.nodeIPs(true))
+	case path == "webhooks" && r.Method == "GET":
+		writeJSON(w, http.StatusOK, append([]updown.Webhook{}, s.webhooks...))
+	case path == "webhooks" && r.Method == "POST":
+		s.createWebhook(w, r)
+	case len(parts) == 2 && parts[0] == "webhooks" && r.Method == "DELETE":
+		s.removeWebhook(w, parts[1])
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (s *Server) serveCheck(w http.ResponseWriter, r *http.Request, token string) {
+	check, found := s.checks[token]
+	if !found {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, check)
+	case "PUT":
+		s.updateCheck(w, r, check)
+	case "DELETE":
+		delete(s.checks, token)
+		delete(s.downtimes, token)
+		delete(s.metrics, token)
+		for i, t := range s.order {
+			if t == token {
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				break
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]bool{"deleted": true})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *Server) createCheck(w http.ResponseWriter, r *http.Request) {
+	var item updown.CheckItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if msg := validateCheck(item.URL, item.Period); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	check := s.putCheck(updown.Check{
+		URL:               item.URL,
+		Alias:             item.Alias,
+		Period:            item.Period,
+		Apdex:             item.Apdex,
+		Enabled:           item.Enabled,
+		Published:         item.Published,
+		StringMatch:       item.StringMatch,
+		MuteUntil:         item.MuteUntil,
+		DisabledLocations: item.DisabledLocations,
+		CustomHeaders:     item.CustomHeaders,
+	})
+	writeJSON(w, http.StatusCreated, check)
+}
+
+// updateCheck only changes the attributes present in the request body, like the real API
+func (s *Server) updateCheck(w http.ResponseWriter, r *http.Request, check updown.Check) {
+	var changes map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&changes); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	current := make(map[string]json.RawMessage)
+	encoded, _ := json.Marshal(check)
+	json.Unmarshal(encoded, &current)
+	for _, attribute := range []string{
+		"url", "period", "apdex_t", "enabled", "published", "alias",
+		"string_match", "mute_until", "disabled_locations", "custom_headers",
+	} {
+		if value, ok := changes[attribute]; ok {
+			current[attribute] = value
+		}
+	}
+
+	var updated updown.Check
+	encoded, _ = json.Marshal(current)
+	if err := json.Unmarshal(encoded, &updated); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid attributes")
+		return
+	}
+	if msg := validateCheck(updated.URL, updated.Period); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	s.checks[check.Token] = updated
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) listDowntimes(w http.ResponseWriter, r *http.Request, token string) {
+	if _, found := s.checks[token]; !found {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	downtimes := s.downtimes[token]
+	start, end := (page-1)*downtimesPerPage, page*downtimesPerPage
+	if start > len(downtimes) {
+		start = len(downtimes)
+	}
+	if end > len(downtimes) {
+		end = len(downtimes)
+	}
+	writeJSON(w, http.StatusOK, append([]updown.Downtime{}, downtimes[start:end]...))
+}
+
+func (s *Server) listMetrics(w http.ResponseWriter, r *http.Request, token string) {
+	if _, found := s.checks[token]; !found {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	if group := r.URL.Query().Get("group"); group != "" && group != "host" && group != "time" {
+		writeError(w, http.StatusBadRequest, "Invalid group, expected host or time")
+		return
+	}
+
+	metrics := s.metrics[token]
+	if metrics == nil {
+		metrics = updown.Metrics{}
+	}
+	writeJSON(w, http.StatusOK, metrics)
+}
+
+func (s *Server) createWebhook(w http.ResponseWriter, r *http.Request) {
+	var webhook updown.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if u, err := url.Parse(webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		writeError(w, http.StatusBadRequest, "URL is invalid")
+		return
+	}
+
+	s.lastID++
+	webhook.ID = fmt.Sprintf("%024x", s.lastID)
+	s.webhooks = append(s.webhooks, webhook)
+	writeJSON(w, http.StatusCreated, webhook)
+}
+
+func (s *Server) removeWebhook(w http.ResponseWriter, id string) {
+	for i, webhook := range s.webhooks {
+		if webhook.ID == id {
+			s.webhooks = append(s.webhooks[:i], s.webhooks[i+1:]...)
+			writeJSON(w, http.StatusOK, map[string]bool{"deleted": true})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Not found")
+}
+
+func (s *Server) putCheck(check updown.Check) updown.Check {
+	if check.Token == "" {
+		s.lastID++
+		check.Token = fmt.Sprintf("t%03x", s.lastID)
+	}
+	if check.Period == 0 {
+		check.Period = 60
+	}
+	if check.Apdex == 0 {
+		check.Apdex = 0.5
+	}
+	if _, exists := s.checks[check.Token]; !exists {
+		s.order = append(s.order, check.Token)
+	}
+	s.checks[check.Token] = check
+	return check
+}
+
+func (s *Server) listChecks() []updown.Check {
+	checks := make([]updown.Check, 0, len(s.order))
+	for _, token := range s.order {
+		checks = append(checks, s.checks[token])
+	}
+	return checks
+}
+
+func (s *Server) nodeIPs(v6 bool) updown.IPs {
+	names := make([]string, 0, len(s.nodes))
+	for name := range s.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ips := updown.IPs{}
+	for _, name := range names {
+		if v6 {
+			ips = append(ips, s.nodes[name].IP6)
+		} else {
+			ips = append(ips, s.nodes[name].IP)
+		}
+	}
+	return ips
+}
+
+func validateCheck(rawURL string, period int) string {
+	if u, err := url.Parse(rawURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return "URL is invalid"
+	}
+	switch period {
+	case 0, 15, 30, 60, 120, 300, 600, 1800, 3600:
+		return ""
+	}
+	return "Period is not included in the list"
+}
+
+func defaultNodes() updown.Nodes {
+	return updown.Nodes{
+		"lan": {IP: "45.32.74.41", IP6: "2001:19f0:6001:2c6::1", City: "Los Angeles", Country: "United States", CountryCode: "US"},
+		"mia": {IP: "104.238.136.194", IP6: "2001:19f0:9002:11a::1", City: "Miami", Country: "United States", CountryCode: "US"},
+		"bhs": {IP: "192.99.37.47", IP6: "2607:5300:60:4c2f::1", City: "Montreal", Country: "Canada", CountryCode: "CA"},
+		"gra": {IP: "91.121.222.175", IP6: "2001:41d0:2:85af::1", City: "Gravelines", Country: "France", CountryCode: "FR"},
+		"fra": {IP: "104.238.159.87", IP6: "2001:19f0:6c01:145::1", City: "Frankfurt", Country: "Germany", CountryCode: "DE"},
+		"sin": {IP: "45.32.107.181", IP6: "2001:19f0:4400:402e::1", City: "Singapore", Country: "Singapore", CountryCode: "SG"},
+		"tok": {IP: "45.76.104.117", IP6: "2001:19f0:7001:45a::1", City: "Tokyo", Country: "Japan", CountryCode: "JP"},
+		"syd": {IP: "45.63.29.207", IP6: "2001:19f0:5801:1d8::1", City: "Sydney", Country: "Australia", CountryCode: "AU"},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
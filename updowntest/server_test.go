@@ -0,0 +1,102 @@
+package updowntest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/antoineaugusti/updown"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecks(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	check, resp, err := client.Check.Add(updown.CheckItem{URL: "https://google.fr", Alias: "Google", Enabled: true})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, 60, check.Period)
+
+	token, err := client.Check.TokenForAlias("Google")
+	assert.Nil(t, err)
+	assert.Equal(t, check.Token, token)
+
+	check, _, err = client.Check.Update(check.Token, updown.CheckItem{URL: "https://google.com", Period: 300})
+	assert.Nil(t, err)
+	assert.Equal(t, "https://google.com", check.URL)
+	assert.Equal(t, 300, check.Period)
+	assert.Equal(t, "Google", check.Alias)
+
+	// Bypass client-side validation
+	req, _ := client.NewRequest("POST", "checks", updown.CheckItem{URL: "google"})
+	resp, err = client.Do(req, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.True(t, errors.Is(err, updown.ErrValidation))
+
+	deleted, _, err := client.Check.Remove(check.Token)
+	assert.Nil(t, err)
+	assert.True(t, deleted)
+
+	_, resp, err = client.Check.Get(check.Token)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.True(t, errors.Is(err, updown.ErrNotFound))
+	assert.Empty(t, server.Checks())
+}
+
+func TestDowntimesAndMetrics(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	check := server.AddCheck(updown.Check{URL: "https://google.fr"})
+	for i := 0; i < 150; i++ {
+		server.AddDowntimes(check.Token, updown.Downtime{Error: "500"})
+	}
+	server.SetMetrics(check.Token, updown.Metrics{"gra": {Apdex: 0.9}})
+
+	downs, _, err := client.Downtime.List(check.Token, 2)
+	assert.Nil(t, err)
+	assert.Len(t, downs, 50)
+
+	metrics, _, err := client.Metric.List(check.Token, "host", "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.9, metrics["gra"].Apdex)
+
+	_, resp, _ := client.Metric.List(check.Token, "foo", "", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestNodesAndWebhooks(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	nodes, _, err := client.Node.List()
+	assert.Nil(t, err)
+	assert.Contains(t, nodes, "gra")
+	ips, _, err := client.Node.ListIPv6()
+	assert.Nil(t, err)
+	assert.Len(t, ips, len(nodes))
+
+	webhook, _, err := client.Webhook.Add(updown.Webhook{URL: "https://example.com/hook"})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, webhook.ID)
+	assert.Len(t, server.Webhooks(), 1)
+
+	deleted, _, err := client.Webhook.Remove(webhook.ID)
+	assert.Nil(t, err)
+	assert.True(t, deleted)
+}
+
+func TestInvalidAPIKey(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	client.APIKey = "foo"
+
+	_, resp, err := client.Check.List()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.True(t, errors.Is(err, updown.ErrUnauthorized))
+}
@@ -0,0 +1,124 @@
+package updown
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Locations lists the abbreviated names of the monitoring locations of updown.io,
+// accepted in DisabledLocations
+var Locations = []string{"lan", "mia", "bhs", "gra", "fra", "sin", "tok", "syd"}
+
+// Values accepted by the API
+var (
+	validPeriods    = []int{15, 30, 60, 120, 300, 600, 1800, 3600}
+	validApdex      = []float64{0.125, 0.25, 0.5, 1.0}
+	validURLSchemes = []string{"http", "https", "tcp", "tcps", "icmp"}
+)
+
+// Validate checks the attributes of a check before sending it to the API.
+// Zero values are accepted, they are not sent or the API uses its defaults.
+// The error is a *ValidationError listing the rejected fields.
+func (c CheckItem) Validate() error {
+	errs := &ValidationError{}
+	validateURL(errs, c.URL)
+	validatePeriod(errs, c.Period)
+	validateApdex(errs, c.Apdex)
+	validateMuteUntil(errs, c.MuteUntil)
+	validateLocations(errs, c.DisabledLocations)
+	return errs.errorOrNil()
+}
+
+// ValidateNew checks the attributes of a check to create. It is like Validate,
+// but the URL is required.
+func (c CheckItem) ValidateNew() error {
+	errs := &ValidationError{}
+	if c.URL == "" {
+		errs.add("url", "cannot be empty")
+	}
+	if err, ok := c.Validate().(*ValidationError); ok {
+		errs.Fields = append(errs.Fields, err.Fields...)
+	}
+	return errs.errorOrNil()
+}
+
+// Validate checks the fields set in a patch before sending it to the API.
+// The error is a *ValidationError listing the rejected fields.
+func (p CheckPatch) Validate() error {
+	errs := &ValidationError{}
+	if p.URL != nil {
+		if *p.URL == "" {
+			errs.add("url", "cannot be empty")
+		}
+		validateURL(errs, *p.URL)
+	}
+	if p.Period != nil {
+		validatePeriod(errs, *p.Period)
+	}
+	if p.Apdex != nil {
+		validateApdex(errs, *p.Apdex)
+	}
+	if p.MuteUntil != nil {
+		validateMuteUntil(errs, *p.MuteUntil)
+	}
+	if p.DisabledLocations != nil {
+		validateLocations(errs, *p.DisabledLocations)
+	}
+	return errs.errorOrNil()
+}
+
+func validateURL(errs *ValidationError, rawURL string) {
+	if rawURL == "" {
+		return
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		errs.add("url", "is invalid")
+		return
+	}
+	if !containsString(validURLSchemes, strings.ToLower(u.Scheme)) {
+		errs.add("url", "must use one of the schemes "+strings.Join(validURLSchemes, ", "))
+	}
+}
+
+func validatePeriod(errs *ValidationError, period int) {
+	if period == 0 {
+		return
+	}
+	for _, valid := range validPeriods {
+		if period == valid {
+			return
+		}
+	}
+	errs.add("period", "must be 15, 30, 60, 120, 300, 600, 1800 or 3600")
+}
+
+func validateApdex(errs *ValidationError, apdex float64) {
+	if apdex == 0 {
+		return
+	}
+	for _, valid := range validApdex {
+		if apdex == valid {
+			return
+		}
+	}
+	errs.add("apdex_t", "must be 0.125, 0.25, 0.5 or 1.0")
+}
+
+func validateMuteUntil(errs *ValidationError, muteUntil string) {
+	switch muteUntil {
+	case "", "recovery", "forever":
+		return
+	}
+	if _, err := parseTime(muteUntil); err != nil {
+		errs.add("mute_until", "must be a time, recovery or forever")
+	}
+}
+
+func validateLocations(errs *ValidationError, locations []string) {
+	for _, location := range locations {
+		if !containsString(Locations, location) {
+			errs.add("disabled_locations", "contains the unknown location "+location)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package updown
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckItemValidate(t *testing.T) {
+	assert.Nil(t, CheckItem{}.Validate())
+	assert.Nil(t, CheckItem{
+		URL:               "https://google.fr",
+		Period:            300,
+		Apdex:             0.125,
+		MuteUntil:         "2016-04-15T14:41:47Z",
+		DisabledLocations: []string{"gra", "syd"},
+	}.Validate())
+	assert.Nil(t, CheckItem{URL: "tcp://google.fr:443", MuteUntil: "recovery"}.Validate())
+	for _, period := range []int{15, 1800, 3600} {
+		assert.Nil(t, CheckItem{Period: period}.Validate())
+	}
+
+	err := CheckItem{
+		URL:               "ftp://google.fr",
+		Period:            45,
+		Apdex:             0.3,
+		MuteUntil:         "tomorrow",
+		DisabledLocations: []string{"gra", "par"},
+	}.Validate()
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	fields := []string{}
+	for _, field := range validationErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	assert.Equal(t, []string{"url", "period", "apdex_t", "mute_until", "disabled_locations"}, fields)
+}
+
+func TestCheckItemValidateNew(t *testing.T) {
+	assert.Nil(t, CheckItem{URL: "https://google.fr"}.ValidateNew())
+
+	err := CheckItem{Period: 45}.ValidateNew()
+	assert.Equal(t, "Invalid parameters: url cannot be empty; period must be 15, 30, 60, 120, 300, 600, 1800 or 3600", err.Error())
+}
+
+func TestCheckPatchValidate(t *testing.T) {
+	assert.Nil(t, CheckPatch{MuteUntil: String(""), Enabled: Bool(false)}.Validate())
+
+	err := CheckPatch{URL: String(""), Period: Int(0)}.Validate()
+	assert.Equal(t, "Invalid parameters: url cannot be empty", err.Error())
+}
+
+func TestAddValidates(t *testing.T) {
+	client := NewClient("key", nil)
+	// Requests are not sent, the base URL is never reached
+	client.BaseURL = nil
+
+	_, resp, err := client.Check.Add(CheckItem{})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	_, resp, err = client.Check.Update("foo", CheckItem{Period: 1})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrValidation))
+}
@@ -0,0 +1,198 @@
+package updown
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// ChangeType is the kind of change of a check noticed by a Watcher
+type ChangeType string
+
+// Changes noticed by a Watcher
+const (
+	ChangeAdded      ChangeType = "added"
+	ChangeRemoved    ChangeType = "removed"
+	ChangeDown       ChangeType = "down"
+	ChangeUp         ChangeType = "up"
+	ChangeStatus     ChangeType = "status"
+	ChangeSSLInvalid ChangeType = "ssl_invalid"
+	ChangeSSLValid   ChangeType = "ssl_valid"
+	ChangeEnabled    ChangeType = "enabled"
+	ChangeDisabled   ChangeType = "disabled"
+	ChangeUptime     ChangeType = "uptime"
+	// A poll failed, see the Err field of the change
+	ChangeError ChangeType = "error"
+)
+
+// DefaultWatchInterval is the interval of a watcher created with an interval which is not positive
+const DefaultWatchInterval = time.Minute
+
+// CheckChange is a change of a check between two polls of a Watcher
+type CheckChange struct {
+	Type  ChangeType
+	Token string
+	// State of the check at the previous poll, zero for added checks
+	Previous Check
+	// State of the check at this poll, zero for removed checks
+	Current Check
+	// Time of the poll which noticed the change
+	At time.Time
+	// Error of the poll, for ChangeError changes
+	Err error
+}
+
+// Watcher polls the checks of a service on an interval, and reports how they changed
+// since the previous poll. It works without webhooks, for instance behind a firewall.
+type Watcher struct {
+	service  *CheckService
+	interval time.Duration
+	now      func() time.Time
+
+	// Minimum variation of the uptime reported, in percentage points. Every variation is
+	// reported when zero. Smaller variations add up until they reach the threshold.
+	UptimeThreshold float64
+
+	mu       sync.Mutex
+	snapshot map[string]Check
+	order    []string
+	// Uptime of each check when its variation was last reported
+	reported map[string]float64
+	err      error
+}
+
+// NewWatcher creates a watcher polling the checks of a service on the given interval,
+// DefaultWatchInterval when it is not positive
+func NewWatcher(service *CheckService, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	return &Watcher{service: service, interval: interval, now: time.Now}
+}
+
+// Watch polls the checks until the context is done, and sends their changes on the returned
+// channel, which is closed once the context is done. The first poll of the watcher gives the
+// initial state of the checks, it does not report changes. Failed polls are sent as ChangeError
+// changes, and the next poll reports the changes since the last successful one.
+func (w *Watcher) Watch(ctx context.Context) <-chan CheckChange {
+	out := make(chan CheckChange)
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			changes, err := w.Poll(ctx)
+			if err != nil && ctx.Err() == nil {
+				changes = []CheckChange{{Type: ChangeError, At: w.now(), Err: err}}
+			}
+			for _, change := range changes {
+				select {
+				case out <- change:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Err returns the error of the last poll, nil when it succeeded
+func (w *Watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.err
+}
+
+// Poll lists the checks once and gives their changes since the previous poll. It is used by Watch,
+// and can be called directly to poll on another schedule, or to get the initial state of the checks
+// before watching them.
+func (w *Watcher) Poll(ctx context.Context) ([]CheckChange, error) {
+	checks, _, err := w.service.ListContext(ctx)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.err = err
+	if err != nil {
+		return nil, err
+	}
+
+	now := w.now()
+	snapshot := make(map[string]Check, len(checks))
+	reported := make(map[string]float64, len(checks))
+	order := make([]string, 0, len(checks))
+	var changes []CheckChange
+	for _, check := range checks {
+		order = append(order, check.Token)
+		snapshot[check.Token] = check
+		previous, known := w.snapshot[check.Token]
+		if !known {
+			reported[check.Token] = check.Uptime
+			if w.snapshot != nil {
+				changes = append(changes, CheckChange{Type: ChangeAdded, Token: check.Token, Current: check, At: now})
+			}
+			continue
+		}
+
+		// Small variations of the uptime add up until they are reported
+		reported[check.Token] = w.reported[check.Token]
+		if w.uptimeChanged(w.reported[check.Token], check.Uptime) {
+			reported[check.Token] = check.Uptime
+		}
+		for _, changeType := range w.diff(previous, check, w.reported[check.Token]) {
+			changes = append(changes, CheckChange{Type: changeType, Token: check.Token, Previous: previous, Current: check, At: now})
+		}
+	}
+
+	for _, token := range w.order {
+		if _, found := snapshot[token]; !found {
+			changes = append(changes, CheckChange{Type: ChangeRemoved, Token: token, Previous: w.snapshot[token], At: now})
+		}
+	}
+
+	w.snapshot, w.order, w.reported = snapshot, order, reported
+	return changes, nil
+}
+
+// diff gives the types of the changes between two states of a check, given the uptime last reported
+func (w *Watcher) diff(previous, current Check, reportedUptime float64) []ChangeType {
+	var changes []ChangeType
+	if previous.Down != current.Down {
+		changes = append(changes, pick(current.Down, ChangeDown, ChangeUp))
+	}
+	if previous.LastStatus != current.LastStatus {
+		changes = append(changes, ChangeStatus)
+	}
+	if previous.SSL.Valid != current.SSL.Valid {
+		changes = append(changes, pick(current.SSL.Valid, ChangeSSLValid, ChangeSSLInvalid))
+	}
+	if previous.Enabled != current.Enabled {
+		changes = append(changes, pick(current.Enabled, ChangeEnabled, ChangeDisabled))
+	}
+	if w.uptimeChanged(reportedUptime, current.Uptime) {
+		changes = append(changes, ChangeUptime)
+	}
+	return changes
+}
+
+func (w *Watcher) uptimeChanged(reported, current float64) bool {
+	variation := math.Abs(current - reported)
+	return variation > 0 && variation >= w.UptimeThreshold
+}
+
+func pick(condition bool, ifTrue, ifFalse ChangeType) ChangeType {
+	if condition {
+		return ifTrue
+	}
+	return ifFalse
+}
@@ -0,0 +1,123 @@
+package updown_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/antoineaugusti/updown"
+	"github.com/antoineaugusti/updown/updowntest"
+	"github.com/stretchr/testify/assert"
+)
+
+func changeTypes(changes []updown.CheckChange) []updown.ChangeType {
+	types := make([]updown.ChangeType, len(changes))
+	for i, change := range changes {
+		types[i] = change.Type
+	}
+	return types
+}
+
+func TestWatcherPoll(t *testing.T) {
+	server := updowntest.NewServer()
+	defer server.Close()
+	google := server.AddCheck(updown.Check{URL: "https://google.fr", LastStatus: 200, Enabled: true, Uptime: 99.9, SSL: updown.SSL{Valid: true}})
+	bing := server.AddCheck(updown.Check{URL: "https://bing.com", Enabled: true})
+
+	watcher := updown.NewWatcher(&server.Client(updown.WithRetryPolicy(updown.RetryPolicy{})).Check, time.Minute)
+	watcher.UptimeThreshold = 0.5
+	ctx := context.Background()
+
+	// The first poll gives the initial state
+	changes, err := watcher.Poll(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, changes)
+
+	yahoo := server.AddCheck(updown.Check{URL: "https://yahoo.com"})
+	changes, _ = watcher.Poll(ctx)
+	assert.Equal(t, []updown.ChangeType{updown.ChangeAdded}, changeTypes(changes))
+	assert.Equal(t, yahoo.Token, changes[0].Token)
+	assert.Equal(t, "https://yahoo.com", changes[0].Current.URL)
+	assert.False(t, changes[0].At.IsZero())
+
+	// Small variations of the uptime are not reported
+	down := google
+	down.Down, down.LastStatus, down.SSL.Valid, down.Enabled, down.Uptime = true, 500, false, false, 99.8
+	server.AddCheck(down)
+	changes, _ = watcher.Poll(ctx)
+	assert.Equal(t, []updown.ChangeType{updown.ChangeDown, updown.ChangeStatus, updown.ChangeSSLInvalid, updown.ChangeDisabled}, changeTypes(changes))
+	assert.Equal(t, google.Token, changes[0].Token)
+	assert.False(t, changes[0].Previous.Down)
+	assert.True(t, changes[0].Current.Down)
+
+	// Until they add up
+	down.Uptime = 99.3
+	server.AddCheck(down)
+	changes, _ = watcher.Poll(ctx)
+	assert.Equal(t, []updown.ChangeType{updown.ChangeUptime}, changeTypes(changes))
+	assert.Equal(t, 99.8, changes[0].Previous.Uptime)
+	assert.Equal(t, 99.3, changes[0].Current.Uptime)
+
+	changes, _ = watcher.Poll(ctx)
+	assert.Empty(t, changes)
+
+	down.Down, down.SSL.Valid, down.Enabled = false, true, true
+	server.AddCheck(down)
+	server.Client().Check.Remove(bing.Token)
+	changes, _ = watcher.Poll(ctx)
+	assert.Equal(t, []updown.ChangeType{updown.ChangeUp, updown.ChangeSSLValid, updown.ChangeEnabled, updown.ChangeRemoved}, changeTypes(changes))
+	assert.Equal(t, bing.Token, changes[3].Token)
+	assert.Equal(t, "https://bing.com", changes[3].Previous.URL)
+	assert.Nil(t, watcher.Err())
+
+	server.Close()
+	_, err = watcher.Poll(ctx)
+	assert.NotNil(t, err)
+	assert.Equal(t, err, watcher.Err())
+}
+
+func TestWatch(t *testing.T) {
+	server := updowntest.NewServer()
+	defer server.Close()
+	check := server.AddCheck(updown.Check{URL: "https://google.fr"})
+
+	watcher := updown.NewWatcher(&server.Client().Check, 5*time.Millisecond)
+	watcher.Poll(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := watcher.Watch(ctx)
+
+	check.Down = true
+	server.AddCheck(check)
+	select {
+	case change := <-changes:
+		assert.Equal(t, updown.ChangeDown, change.Type)
+		assert.Equal(t, check.Token, change.Token)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a change")
+	}
+
+	// The channel is closed once the context is done
+	cancel()
+	for range changes {
+	}
+}
+
+func TestWatchFailures(t *testing.T) {
+	server := updowntest.NewServer()
+	client := server.Client(updown.WithRetryPolicy(updown.RetryPolicy{}))
+	server.Close()
+
+	// A watcher without interval polls every DefaultWatchInterval
+	watcher := updown.NewWatcher(&client.Check, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	select {
+	case change := <-watcher.Watch(ctx):
+		assert.Equal(t, updown.ChangeError, change.Type)
+		assert.NotNil(t, change.Err)
+		assert.Equal(t, change.Err, watcher.Err())
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected an error")
+	}
+}
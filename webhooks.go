@@ -1,6 +1,7 @@
 package updown
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -11,6 +12,27 @@ type Webhook struct {
 	URL string `json:"url,omitempty"`
 }
 
+// WebhookSyncOptions configures how webhooks are synchronized
+type WebhookSyncOptions struct {
+	// Only compute the changes, without applying them
+	DryRun bool
+}
+
+// WebhookSyncReport describes the changes made to webhooks
+type WebhookSyncReport struct {
+	// Webhooks created, without ID in dry-run mode
+	Created []Webhook
+	// Webhooks which already existed
+	Existing []Webhook
+	// Webhooks removed, because their URL is not desired or is a duplicate
+	Removed []Webhook
+}
+
+// HasChanges tells if webhooks were created or removed
+func (r WebhookSyncReport) HasChanges() bool {
+	return len(r.Created)+len(r.Removed) > 0
+}
+
 // WebhookService interacts with the webhooks section of the API
 type WebhookService struct {
 	client *Client
@@ -18,7 +40,12 @@ type WebhookService struct {
 
 // List lists all the webhooks
 func (s *WebhookService) List() ([]Webhook, *http.Response, error) {
-	req, err := s.client.NewRequest("GET", "webhooks", nil)
+	return s.ListContext(context.Background())
+}
+
+// ListContext is like List but takes a context for the request
+func (s *WebhookService) ListContext(ctx context.Context) ([]Webhook, *http.Response, error) {
+	req, err := s.client.NewRequestWithContext(ctx, "GET", "webhooks", nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -34,7 +61,12 @@ func (s *WebhookService) List() ([]Webhook, *http.Response, error) {
 
 // Add adds a new webhook you want to be performed
 func (s *WebhookService) Add(webhook Webhook) (Webhook, *http.Response, error) {
-	req, err := s.client.NewRequest("POST", "webhooks", webhook)
+	return s.AddContext(context.Background(), webhook)
+}
+
+// AddContext is like Add but takes a context for the request
+func (s *WebhookService) AddContext(ctx context.Context, webhook Webhook) (Webhook, *http.Response, error) {
+	req, err := s.client.NewRequestWithContext(ctx, "POST", "webhooks", webhook)
 	if err != nil {
 		return webhook, nil, err
 	}
@@ -50,7 +82,12 @@ func (s *WebhookService) Add(webhook Webhook) (Webhook, *http.Response, error) {
 
 // Remove removes a webhook from Updown by its ID
 func (s *WebhookService) Remove(id string) (bool, *http.Response, error) {
-	req, err := s.client.NewRequest("DELETE", fmt.Sprintf("webhooks/%s", id), nil)
+	return s.RemoveContext(context.Background(), id)
+}
+
+// RemoveContext is like Remove but takes a context for the request
+func (s *WebhookService) RemoveContext(ctx context.Context, id string) (bool, *http.Response, error) {
+	req, err := s.client.NewRequestWithContext(ctx, "DELETE", fmt.Sprintf("webhooks/%s", id), nil)
 	if err != nil {
 		return false, nil, err
 	}
@@ -66,3 +103,98 @@ func (s *WebhookService) Remove(id string) (bool, *http.Response, error) {
 
 	return res.Deleted, resp, err
 }
+
+// Ensure adds a webhook for a URL, unless one already exists. The existing or created webhook is returned.
+func (s *WebhookService) Ensure(url string) (Webhook, *http.Response, error) {
+	return s.EnsureContext(context.Background(), url)
+}
+
+// EnsureContext is like Ensure but takes a context for the requests
+func (s *WebhookService) EnsureContext(ctx context.Context, url string) (Webhook, *http.Response, error) {
+	if url == "" {
+		return Webhook{}, nil, &ValidationError{Fields: []FieldError{{Field: "url", Message: "cannot be empty"}}}
+	}
+
+	webhooks, resp, err := s.ListContext(ctx)
+	if err != nil {
+		return Webhook{}, resp, err
+	}
+	for _, webhook := range webhooks {
+		if webhook.URL == url {
+			return webhook, resp, nil
+		}
+	}
+	return s.AddContext(ctx, Webhook{URL: url})
+}
+
+// Sync makes the webhooks match the given URLs: missing webhooks are added, then webhooks for
+// other URLs are removed, as well as duplicate webhooks for the same URL. It stops at the first error.
+func (s *WebhookService) Sync(urls []string, opts WebhookSyncOptions) (WebhookSyncReport, error) {
+	return s.SyncContext(context.Background(), urls, opts)
+}
+
+// SyncContext is like Sync but takes a context for the requests
+func (s *WebhookService) SyncContext(ctx context.Context, urls []string, opts WebhookSyncOptions) (WebhookSyncReport, error) {
+	report, unwanted, err := s.ensureAll(ctx, urls, opts.DryRun)
+	if err != nil {
+		return report, err
+	}
+
+	for _, webhook := range unwanted {
+		if !opts.DryRun {
+			if _, _, err := s.RemoveContext(ctx, webhook.ID); err != nil {
+				return report, err
+			}
+		}
+		report.Removed = append(report.Removed, webhook)
+	}
+	return report, nil
+}
+
+// ensureAll adds the webhooks for the URLs which do not exist yet. The existing webhooks whose URL
+// is not given, or which duplicate another webhook, are returned apart.
+func (s *WebhookService) ensureAll(ctx context.Context, urls []string, dryRun bool) (WebhookSyncReport, []Webhook, error) {
+	var report WebhookSyncReport
+	errs := &ValidationError{}
+	wanted := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		if url == "" {
+			errs.add("url", "cannot be empty")
+		}
+		wanted[url] = true
+	}
+	if err := errs.errorOrNil(); err != nil {
+		return report, nil, err
+	}
+
+	webhooks, _, err := s.ListContext(ctx)
+	if err != nil {
+		return report, nil, err
+	}
+
+	found := make(map[string]bool, len(webhooks))
+	var unwanted []Webhook
+	for _, webhook := range webhooks {
+		if !wanted[webhook.URL] || found[webhook.URL] {
+			unwanted = append(unwanted, webhook)
+			continue
+		}
+		found[webhook.URL] = true
+		report.Existing = append(report.Existing, webhook)
+	}
+
+	for _, url := range urls {
+		if found[url] {
+			continue
+		}
+		webhook := Webhook{URL: url}
+		if !dryRun {
+			if webhook, _, err = s.AddContext(ctx, webhook); err != nil {
+				return report, unwanted, err
+			}
+		}
+		found[url] = true
+		report.Created = append(report.Created, webhook)
+	}
+	return report, unwanted, nil
+}
@@ -0,0 +1,71 @@
+package updown_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/antoineaugusti/updown"
+	"github.com/antoineaugusti/updown/updowntest"
+	"github.com/stretchr/testify/assert"
+)
+
+func webhookURLs(webhooks []updown.Webhook) []string {
+	urls := make([]string, len(webhooks))
+	for i, webhook := range webhooks {
+		urls[i] = webhook.URL
+	}
+	return urls
+}
+
+func TestEnsureWebhook(t *testing.T) {
+	server := updowntest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	created, _, err := client.Webhook.Ensure("https://example.com/hook")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, created.ID)
+
+	existing, _, err := client.Webhook.Ensure("https://example.com/hook")
+	assert.Nil(t, err)
+	assert.Equal(t, created, existing)
+	assert.Len(t, server.Webhooks(), 1)
+
+	_, _, err = client.Webhook.Ensure("")
+	assert.True(t, errors.Is(err, updown.ErrValidation))
+}
+
+func TestSyncWebhooks(t *testing.T) {
+	server := updowntest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	client.Webhook.Add(updown.Webhook{URL: "https://example.com/a"})
+	client.Webhook.Add(updown.Webhook{URL: "https://example.com/old"})
+	client.Webhook.Add(updown.Webhook{URL: "https://example.com/a"})
+
+	desired := []string{"https://example.com/a", "https://example.com/b"}
+	report, err := client.Webhook.Sync(desired, updown.WebhookSyncOptions{DryRun: true})
+	assert.Nil(t, err)
+	assert.True(t, report.HasChanges())
+	assert.Equal(t, []string{"https://example.com/b"}, webhookURLs(report.Created))
+	assert.Equal(t, []string{"https://example.com/a"}, webhookURLs(report.Existing))
+	assert.Equal(t, []string{"https://example.com/old", "https://example.com/a"}, webhookURLs(report.Removed))
+	assert.Len(t, server.Webhooks(), 3)
+
+	report, err = client.Webhook.Sync(desired, updown.WebhookSyncOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, report.Created, 1)
+	assert.NotEmpty(t, report.Created[0].ID)
+	assert.Len(t, report.Removed, 2)
+	assert.Equal(t, desired, webhookURLs(server.Webhooks()))
+
+	// Nothing changes once in sync
+	report, err = client.Webhook.Sync(desired, updown.WebhookSyncOptions{})
+	assert.Nil(t, err)
+	assert.False(t, report.HasChanges())
+
+	report, err = client.Webhook.Sync(nil, updown.WebhookSyncOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, report.Removed, 2)
+	assert.Empty(t, server.Webhooks())
+}